@@ -1,22 +1,28 @@
 package main
 
 import (
-	"encoding/csv"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"maps"
 	"os"
 	"path"
 	"runtime/pprof"
 	"strings"
-	"text/template"
+	"sync"
 	"time"
 
+	"github.com/codingconcepts/dg/internal/pkg/ddl"
+	"github.com/codingconcepts/dg/internal/pkg/dialect"
 	"github.com/codingconcepts/dg/internal/pkg/generator"
 	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/codingconcepts/dg/internal/pkg/random"
+	"github.com/codingconcepts/dg/internal/pkg/schema"
 	"github.com/codingconcepts/dg/internal/pkg/source"
 	"github.com/codingconcepts/dg/internal/pkg/ui"
 	"github.com/codingconcepts/dg/internal/pkg/web"
+	"github.com/codingconcepts/dg/internal/pkg/writer"
 	"github.com/samber/lo"
 )
 
@@ -38,13 +44,42 @@ func (i *arrayFlags) Set(value string) error {
 func main() {
 	log.SetFlags(0)
 
+	if len(os.Args) > 1 && os.Args[1] == "init" {
+		if err := runInit(os.Args[2:]); err != nil {
+			log.Fatalf("error creating config: %v", err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		if err := runValidate(os.Args[2:]); err != nil {
+			log.Fatalf("error validating config: %v", err)
+		}
+		return
+	}
+
 	var configPaths arrayFlags
 	flag.Var(&configPaths, "c", "the absolute or relative path to the config file (can be used multiple times)")
+	var varFlags arrayFlags
+	flag.Var(&varFlags, "var", "set a config var, as name=value (can be used multiple times, overrides "+model.VarEnvPrefix+"name environment variables)")
 	outputDir := flag.String("o", ".", "the absolute or relative path to the output dir")
 	createImports := flag.String("i", "", "write import statements to file")
+	importDialect := flag.String("i-dialect", writer.ImportCockroachDB, "the database to write import statements for (cockroachdb, cockroachdb-http, cockroachdb-nodelocal, postgres, mysql, sqlite or duckdb)")
 	cpuprofile := flag.String("cpuprofile", "", "write cpu profile to file")
 	versionFlag := flag.Bool("version", false, "display the current version number")
 	port := flag.Int("p", 0, "port to serve files from (omit to generate without serving)")
+	createInserts := flag.String("inserts", "", "write insert statements for all tables to file")
+	createDDL := flag.String("ddl", "", "write create table statements to file")
+	dialectName := flag.String("dialect", dialect.CockroachDB, "the sql dialect of insert and create table statements (cockroachdb, postgres, mysql, sqlite, sqlserver or duckdb)")
+	batchSize := flag.Int("batch", writer.DefaultBatchSize, "the number of rows per insert statement")
+	format := flag.String("format", writer.FormatCSV, "the output file format (csv, json, ndjson or sql)")
+	empty := flag.String("empty", writer.EmptyNull, "how empty values are written to json and ndjson files (null, omit or string)")
+	stream := flag.Bool("stream", false, "write each table as soon as it's generated, generating tables that other tables don't read whole records from a chunk at a time")
+	chunkSize := flag.Int("chunk", 10000, "the number of rows to generate at a time when streaming")
+	seed := flag.Int64("seed", 0, "seed for random data, making output reproducible (overrides the config's seed)")
+	workers := flag.Int("workers", 1, "the number of tables, and blocks of rows within a column, to generate at a time")
+	scale := flag.Float64("scale", 1, "multiply the count of every table that isn't fixed by this factor")
+	scaleProcessors := flag.Bool("scale-processors", false, "also multiply fk repeat counts, and the number of combinations of each tables without a count, by the -scale factor")
 	flag.Parse()
 
 	if *cpuprofile != "" {
@@ -61,38 +96,95 @@ func main() {
 		os.Exit(2)
 	}
 
+	d, err := dialect.Get(*dialectName)
+	if err != nil {
+		log.Fatalf("error parsing dialect: %v", err)
+	}
+	sqlOptions := writer.Options{Dialect: d, BatchSize: *batchSize}
+
+	if *scale <= 0 {
+		log.Fatalf("invalid scale %v (expected a positive number)", *scale)
+	}
+
 	tt := ui.TimeTracker(os.Stdout, realClock{}, 40)
 	defer tt(time.Now(), "done")
 
-	c, err := loadConfigs(configPaths, tt)
+	c, err := loadConfigs(configPaths, varFlags, tt)
 	if err != nil {
 		log.Fatalf("error loading configs: %v", err)
 	}
 
+	c.Scale(*scale)
+	if *scaleProcessors {
+		generator.SetScale(*scale)
+	}
+
+	if c.Tables, err = generator.SortTables(c.Tables); err != nil {
+		log.Fatalf("error ordering tables: %v", err)
+	}
+
+	// The flag only overrides the config's seed when it's given, so that
+	// -seed 0 is a seed like any other.
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "seed" {
+			c.Seed = seed
+		}
+	})
+	if c.Seed != nil {
+		random.Seed(*c.Seed)
+	}
+
+	generator.SetWorkers(*workers)
+
 	files := make(map[string]model.CSVFile)
 
 	if err = loadInputs(c, path.Dir(configPaths[0]), tt, files); err != nil {
 		log.Fatalf("error loading inputs: %v", err)
 	}
 
-	if err = generateTables(c, tt, files); err != nil {
-		log.Fatalf("error generating tables: %v", err)
-	}
+	output := model.Output{Format: *format, Empty: *empty}
 
-	if err = removeSuppressedColumns(c, tt, files); err != nil {
-		log.Fatalf("error removing supressed columns: %v", err)
-	}
+	if *stream {
+		if err = streamTables(c, *outputDir, *createInserts, output, sqlOptions, *chunkSize, tt, files); err != nil {
+			log.Fatalf("error streaming tables: %v", err)
+		}
+	} else {
+		if err = generateTables(c, *workers, tt, files); err != nil {
+			log.Fatalf("error generating tables: %v", err)
+		}
 
-	if err = reorderColumns(c, tt, files); err != nil {
-		log.Fatalf("error validating files: %v", err)
+		if err = validateDataTypes(c, tt, files); err != nil {
+			log.Fatalf("error validating data types: %v", err)
+		}
+
+		if err = removeSuppressedColumns(c, tt, files); err != nil {
+			log.Fatalf("error removing supressed columns: %v", err)
+		}
+
+		if err = reorderColumns(c, tt, files); err != nil {
+			log.Fatalf("error validating files: %v", err)
+		}
+
+		if err := writeFiles(c, *outputDir, output, sqlOptions, files, tt); err != nil {
+			log.Fatalf("error writing files: %v", err)
+		}
+
+		if *createInserts != "" {
+			if err := writeInserts(*outputDir, *createInserts, c, sqlOptions, files, tt); err != nil {
+				log.Fatalf("error writing insert statements: %v", err)
+			}
+		}
 	}
 
-	if err := writeFiles(*outputDir, files, tt); err != nil {
-		log.Fatalf("error writing csv files: %v", err)
+	if *createDDL != "" {
+		if err := writeDDL(*outputDir, *createDDL, c, d, tt); err != nil {
+			log.Fatalf("error writing create table statements: %v", err)
+		}
 	}
 
 	if *createImports != "" {
-		if err := writeImports(*outputDir, *createImports, c, files, tt); err != nil {
+		importOptions := writer.ImportOptions{Dialect: *importDialect, Port: *port}
+		if err := writeImports(*outputDir, *createImports, c, output, importOptions, files, tt); err != nil {
 			log.Fatalf("error writing import statements: %v", err)
 		}
 	}
@@ -104,27 +196,113 @@ func main() {
 	log.Fatal(web.Serve(*outputDir, *port))
 }
 
-func loadConfigs(filenames []string, tt ui.TimerFunc) (model.Config, error) {
-	defer tt(time.Now(), "loaded config files")
+// runInit creates a config from the CREATE TABLE statements in a SQL file.
+func runInit(args []string) error {
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	fromDDL := fs.String("from-ddl", "", "the absolute or relative path to a sql file of create table statements")
+	output := fs.String("o", "", "the absolute or relative path to write the config file to (omit to write to stdout)")
+	count := fs.Int("count", 100, "the number of rows to generate for each table")
+	fs.Parse(args)
 
-	var mergedConfig model.Config
+	if *fromDDL == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
 
-	for _, filename := range filenames {
-		file, err := os.Open(filename)
-		if err != nil {
-			return model.Config{}, fmt.Errorf("opening file %s: %w", filename, err)
-		}
-		defer file.Close()
+	file, err := os.Open(*fromDDL)
+	if err != nil {
+		return fmt.Errorf("opening ddl file: %w", err)
+	}
+	defer file.Close()
 
-		config, err := model.LoadConfig(file, path.Dir(filename))
-		if err != nil {
-			return model.Config{}, fmt.Errorf("loading config from %s: %w", filename, err)
+	tables, err := ddl.Parse(file)
+	if err != nil {
+		return fmt.Errorf("parsing ddl file: %w", err)
+	}
+
+	c, err := ddl.ToConfig(tables, *count)
+	if err != nil {
+		return fmt.Errorf("building config: %w", err)
+	}
+
+	if *output == "" {
+		return ddl.WriteConfig(os.Stdout, c)
+	}
+
+	out, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("creating config file: %w", err)
+	}
+	defer out.Close()
+
+	return ddl.WriteConfig(out, c)
+}
+
+// runValidate checks configs without generating any data, reporting every
+// problem found.
+func runValidate(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	var configPaths arrayFlags
+	fs.Var(&configPaths, "c", "the absolute or relative path to the config file (can be used multiple times)")
+	var varFlags arrayFlags
+	fs.Var(&varFlags, "var", "set a config var, as name=value (can be used multiple times, overrides "+model.VarEnvPrefix+"name environment variables)")
+	fs.Parse(args)
+
+	if len(configPaths) == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var problems []error
+	for _, filename := range configPaths {
+		problems = append(problems, model.Errors(model.CheckConfigFile(filename))...)
+	}
+
+	tt := ui.TimeTracker(io.Discard, realClock{}, 40)
+
+	c, err := loadConfigs(configPaths, varFlags, tt)
+	if err != nil {
+		return err
+	}
+
+	files := make(map[string]model.CSVFile)
+	for _, input := range c.Inputs {
+		if err := loadInput(input, path.Dir(configPaths[0]), tt, files); err != nil {
+			problems = append(problems, input.Position.Wrap(fmt.Errorf("loading input %q: %w", input.Name, err)))
+			files[input.Name] = model.CSVFile{Name: input.Name}
 		}
+	}
+
+	problems = append(problems, model.Errors(generator.Validate(c, files))...)
 
-		mergedConfig = model.MergeConfig(mergedConfig, config)
+	if len(problems) == 0 {
+		fmt.Println("config is valid")
+		return nil
 	}
 
-	return mergedConfig, nil
+	for _, p := range problems {
+		fmt.Println(p)
+	}
+	return fmt.Errorf("found %d problem(s)", len(problems))
+}
+
+// loadConfigs loads and merges config files, overriding their vars with
+// those of the environment and then those of -var flags.
+func loadConfigs(filenames []string, varFlags []string, tt ui.TimerFunc) (model.Config, error) {
+	defer tt(time.Now(), "loaded config files")
+
+	vars, err := model.ParseVars(append(model.EnvVars(os.Environ()), varFlags...))
+	if err != nil {
+		return model.Config{}, fmt.Errorf("parsing vars: %w", err)
+	}
+
+	c, err := model.LoadConfigFiles(filenames, vars)
+	if err != nil {
+		return model.Config{}, err
+	}
+
+	generator.SetVars(c.Vars)
+	return c, nil
 }
 
 func loadInputs(c model.Config, configDir string, tt ui.TimerFunc, files map[string]model.CSVFile) error {
@@ -132,7 +310,7 @@ func loadInputs(c model.Config, configDir string, tt ui.TimerFunc, files map[str
 
 	for _, input := range c.Inputs {
 		if err := loadInput(input, configDir, tt, files); err != nil {
-			return fmt.Errorf("loading input for %q: %w", input.Name, err)
+			return input.Position.Wrap(fmt.Errorf("loading input for %q: %w", input.Name, err))
 		}
 	}
 
@@ -157,52 +335,159 @@ func loadInput(input model.Input, configDir string, tt ui.TimerFunc, files map[s
 	return nil
 }
 
-func generateTables(c model.Config, tt ui.TimerFunc, files map[string]model.CSVFile) error {
+// generateTables generates up to workers tables at a time, starting each
+// table once the tables it reads from have been generated. Each table is
+// generated into its own copy of files, so that tables being generated at
+// the same time don't write to the same map.
+func generateTables(c model.Config, workers int, tt ui.TimerFunc, files map[string]model.CSVFile) error {
 	defer tt(time.Now(), "generated all tables")
 
+	if workers <= 1 {
+		for _, table := range c.Tables {
+			if err := generateTable(table, files, tt); err != nil {
+				return table.Position.Wrap(fmt.Errorf("generating csv file for %q: %w", table.Name, err))
+			}
+		}
+		return nil
+	}
+
+	// Share index caches between the copies of files.
+	model.CacheIndexes(files)
+
+	done := make(map[string]chan struct{}, len(c.Tables))
 	for _, table := range c.Tables {
-		if err := generateTable(table, files, tt); err != nil {
-			return fmt.Errorf("generating csv file for %q: %w", table.Name, err)
+		done[table.Name] = make(chan struct{})
+	}
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		errs   = make([]error, len(c.Tables))
+		failed = make(chan struct{})
+		fail   sync.Once
+		sem    = make(chan struct{}, workers)
+	)
+
+	for i, table := range c.Tables {
+		deps, err := generator.ReadsFrom(table)
+		if err != nil {
+			return err
+		}
+
+		// A table that reads from dynamically named tables could read from
+		// any table ordered before it.
+		dynamic, err := generator.ReadsDynamicTables(table)
+		if err != nil {
+			return err
 		}
+		if dynamic {
+			deps = lo.Map(c.Tables[:i], func(t model.Table, _ int) string {
+				return t.Name
+			})
+		}
+
+		wg.Add(1)
+		go func(i int, table model.Table) {
+			defer wg.Done()
+
+			// Inputs have already been loaded, so only wait for tables.
+			for _, d := range deps {
+				ch, ok := done[d]
+				if !ok {
+					continue
+				}
+				select {
+				case <-ch:
+				case <-failed:
+					return
+				}
+			}
+
+			select {
+			case sem <- struct{}{}:
+			case <-failed:
+				return
+			}
+			defer func() { <-sem }()
+
+			mu.Lock()
+			tableFiles := maps.Clone(files)
+			mu.Unlock()
+
+			if err := generateTable(table, tableFiles, tt); err != nil {
+				errs[i] = table.Position.Wrap(fmt.Errorf("generating csv file for %q: %w", table.Name, err))
+				fail.Do(func() { close(failed) })
+				return
+			}
+			model.CacheIndexes(tableFiles)
+
+			mu.Lock()
+			files[table.Name] = tableFiles[table.Name]
+			mu.Unlock()
+
+			close(done[table.Name])
+		}(i, table)
 	}
+	wg.Wait()
 
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 func reorderColumns(c model.Config, tt ui.TimerFunc, files map[string]model.CSVFile) error {
 	defer tt(time.Now(), "reorder all table columns")
 
-	for _, model := range c.Tables {
-		file, ok := files[model.Name]
+	for _, table := range c.Tables {
+		file, ok := files[table.Name]
 		if !ok {
 			continue // Skip if the file doesn't exist
 		}
-		newHeader := make([]string, len(file.Header))
-		newLines := make([][]string, len(file.Lines))
-		i := 0
-		for _, col := range model.Columns {
-			if col.Suppress {
-				continue
-			}
-			currentIndex := lo.IndexOf(file.Header, col.Name)
-			if currentIndex < 0 || currentIndex > len(file.Lines) {
-				return fmt.Errorf("column %s not found in file %s", col.Name, file.Name)
-			}
-			newHeader[i] = col.Name
-			newLines[i] = file.Lines[currentIndex]
-			i++
+
+		file, err := reorderTable(table, file)
+		if err != nil {
+			return err
 		}
-		file.Header = newHeader
-		file.Lines = newLines
-		files[model.Name] = file
+		files[table.Name] = file
 	}
 
 	return nil
 }
 
+// reorderTable puts a table's columns in the order they're declared in,
+// leaving out suppressed columns.
+func reorderTable(table model.Table, file model.CSVFile) (model.CSVFile, error) {
+	newHeader := make([]string, len(file.Header))
+	newLines := make([][]string, len(file.Lines))
+	i := 0
+	for _, col := range table.Columns {
+		if col.Suppress {
+			continue
+		}
+		currentIndex := lo.IndexOf(file.Header, col.Name)
+		if currentIndex < 0 || currentIndex > len(file.Lines) {
+			return model.CSVFile{}, fmt.Errorf("column %s not found in file %s", col.Name, file.Name)
+		}
+		newHeader[i] = col.Name
+		newLines[i] = file.Lines[currentIndex]
+		i++
+	}
+	file.Header = newHeader[:i]
+	file.Lines = newLines[:i]
+	return file, nil
+}
+
 func generateTable(t model.Table, files map[string]model.CSVFile, tt ui.TimerFunc) error {
 	defer tt(time.Now(), fmt.Sprintf("generated table: %s", t.Name))
 
+	// Work out counts that depend on the tables generated so far.
+	if err := generator.ResolveCount(&t, files); err != nil {
+		return err
+	}
+
 	// Create any foreign_key columns next
 	var fk generator.ForeignKeyGenerator
 	if err := fk.Generate(t, files); err != nil {
@@ -221,156 +506,234 @@ func generateTable(t model.Table, files map[string]model.CSVFile, tt ui.TimerFun
 		return fmt.Errorf("generating const columns: %w", err)
 	}
 
-	for _, col := range t.Columns {
-		switch col.Type {
-		case "ref":
-			var g generator.RefGenerator
-			if err := col.Generator.UnmarshalFunc(&g); err != nil {
-				return fmt.Errorf("parsing ref process for %s.%s: %w", t.Name, col.Name, err)
-			}
-			if err := g.Generate(t, col, files); err != nil {
-				return fmt.Errorf("running ref process for %s.%s: %w", t.Name, col.Name, err)
-			}
+	// Generate the remaining columns after the columns they read from.
+	columns, err := generator.SortColumns(t)
+	if err != nil {
+		return fmt.Errorf("ordering columns: %w", err)
+	}
 
-		case "gen":
-			var g generator.GenGenerator
-			if err := col.Generator.UnmarshalFunc(&g); err != nil {
-				return fmt.Errorf("parsing each process for %s: %w", col.Name, err)
-			}
-			if err := g.Generate(t, col, files); err != nil {
-				return fmt.Errorf("running gen process for %s.%s: %w", t.Name, col.Name, err)
-			}
+	for _, col := range columns {
+		if err := generateColumn(t, col, files); err != nil {
+			return col.Position.Wrap(err)
+		}
+	}
 
-		case "set":
-			var g generator.SetGenerator
-			if err := col.Generator.UnmarshalFunc(&g); err != nil {
-				return fmt.Errorf("parsing set process for %s.%s: %w", t.Name, col.Name, err)
-			}
-			if err := g.Generate(t, col, files); err != nil {
-				return fmt.Errorf("running set process for %s.%s: %w", t.Name, col.Name, err)
-			}
+	file, ok := files[t.Name]
+	if !ok {
+		return fmt.Errorf("missing table: %q", t.Name)
+	}
 
-		case "inc":
-			var g generator.IncGenerator
-			if err := col.Generator.UnmarshalFunc(&g); err != nil {
-				return fmt.Errorf("parsing each process for %s: %w", col.Name, err)
-			}
-			if err := g.Generate(t, col, files); err != nil {
-				return fmt.Errorf("running inc process for %s.%s: %w", t.Name, col.Name, err)
-			}
+	switch t.UniqueMode {
+	case "", model.UniqueDrop:
+		if len(file.UniqueColumns) > 0 {
+			file.Lines = file.Unique()
+		}
+		files[t.Name] = file
 
-		case "range":
-			var g generator.RangeGenerator
-			if err := col.Generator.UnmarshalFunc(&g); err != nil {
-				return fmt.Errorf("parsing range process for %s: %w", col.Name, err)
-			}
-			if err := g.Generate(t, col, files); err != nil {
-				return fmt.Errorf("running range process for %s.%s: %w", t.Name, col.Name, err)
+	case model.UniqueRetry:
+		if len(t.UniqueColumns) > 0 {
+			if err := generator.RetryUnique(t, files); err != nil {
+				return fmt.Errorf("making rows unique: %w", err)
 			}
+		}
 
-		case "match":
-			var g generator.MatchGenerator
-			if err := col.Generator.UnmarshalFunc(&g); err != nil {
-				return fmt.Errorf("parsing match process for %s: %w", col.Name, err)
-			}
-			if err := g.Generate(t, col, files); err != nil {
-				return fmt.Errorf("running match process for %s.%s: %w", t.Name, col.Name, err)
-			}
+	default:
+		return fmt.Errorf("invalid unique_mode %q (expected %s or %s)", t.UniqueMode, model.UniqueDrop, model.UniqueRetry)
+	}
 
-		case "cuid2":
-			var g generator.Cuid2Generator
-			if err := col.Generator.UnmarshalFunc(&g); err != nil {
-				return fmt.Errorf("parsing cuid2 process for %s: %w", col.Name, err)
-			}
-			if err := g.Generate(t, col, files); err != nil {
-				return fmt.Errorf("running cuid2 process for %s.%s: %w", t.Name, col.Name, err)
-			}
+	// Remove rows that don't match the table's where expression, before
+	// other tables read from it.
+	if err := generator.FilterRows(t, files); err != nil {
+		return fmt.Errorf("filtering rows: %w", err)
+	}
 
-		case "rel_date", "relative_date":
-			var g generator.RelDateGenerator
-			if err := col.Generator.UnmarshalFunc(&g); err != nil {
-				return fmt.Errorf("parsing rel_date process for %s: %w", col.Name, err)
-			}
-			if err := g.Generate(t, col, files); err != nil {
-				return fmt.Errorf("running rel_date process for %s.%s: %w", t.Name, col.Name, err)
-			}
+	return nil
+}
 
-		case "rand":
-			var g generator.RandGenerator
-			if err := col.Generator.UnmarshalFunc(&g); err != nil {
-				return fmt.Errorf("parsing rand process for %s: %w", col.Name, err)
-			}
-			if err := g.Generate(t, col, files); err != nil {
-				return fmt.Errorf("running rand process for %s.%s: %w", t.Name, col.Name, err)
-			}
+// generateColumn generates a column that isn't an fk, each or const column,
+// which are generated before any other.
+func generateColumn(t model.Table, col model.Column, files map[string]model.CSVFile) error {
+	switch col.Type {
+	case "ref":
+		var g generator.RefGenerator
+		if err := col.Generator.UnmarshalFunc(&g); err != nil {
+			return fmt.Errorf("parsing ref process for %s.%s: %w", t.Name, col.Name, err)
+		}
+		if err := g.Generate(t, col, files); err != nil {
+			return fmt.Errorf("running ref process for %s.%s: %w", t.Name, col.Name, err)
+		}
 
-		case "expr":
-			var g generator.ExprGenerator
-			if err := col.Generator.UnmarshalFunc(&g); err != nil {
-				return fmt.Errorf("parsing expr process for %s: %w", col.Name, err)
-			}
-			if err := g.Generate(t, col, files); err != nil {
-				return fmt.Errorf("running expr process for %s.%s: %w", t.Name, col.Name, err)
-			}
+	case "gen":
+		var g generator.GenGenerator
+		if err := col.Generator.UnmarshalFunc(&g); err != nil {
+			return fmt.Errorf("parsing each process for %s: %w", col.Name, err)
+		}
+		if err := g.Generate(t, col, files); err != nil {
+			return fmt.Errorf("running gen process for %s.%s: %w", t.Name, col.Name, err)
+		}
 
-		case "case":
-			var g generator.CaseGenerator
-			if err := col.Generator.UnmarshalFunc(&g); err != nil {
-				return fmt.Errorf("parsing case process for %s: %w", col.Name, err)
-			}
-			if err := g.Generate(t, col, files); err != nil {
-				return fmt.Errorf("running case process for %s.%s: %w", t.Name, col.Name, err)
-			}
+	case "set":
+		var g generator.SetGenerator
+		if err := col.Generator.UnmarshalFunc(&g); err != nil {
+			return fmt.Errorf("parsing set process for %s.%s: %w", t.Name, col.Name, err)
+		}
+		if err := g.Generate(t, col, files); err != nil {
+			return fmt.Errorf("running set process for %s.%s: %w", t.Name, col.Name, err)
+		}
 
-		case "map":
-			var g generator.MapGenerator
-			if err := col.Generator.UnmarshalFunc(&g); err != nil {
-				return fmt.Errorf("parsing map process for %s: %w", col.Name, err)
-			}
-			if err := g.Generate(t, col, files); err != nil {
-				return fmt.Errorf("running map process for %s.%s: %w", t.Name, col.Name, err)
-			}
+	case "inc":
+		var g generator.IncGenerator
+		if err := col.Generator.UnmarshalFunc(&g); err != nil {
+			return fmt.Errorf("parsing each process for %s: %w", col.Name, err)
+		}
+		if err := g.Generate(t, col, files); err != nil {
+			return fmt.Errorf("running inc process for %s.%s: %w", t.Name, col.Name, err)
+		}
 
-		case "pick":
-			var g generator.PickGenerator
-			if err := col.Generator.UnmarshalFunc(&g); err != nil {
-				return fmt.Errorf("parsing once process for %s: %w", col.Name, err)
-			}
-			if err := g.Generate(t, col, files); err != nil {
-				return fmt.Errorf("running once process for %s.%s: %w", t.Name, col.Name, err)
-			}
+	case "range":
+		var g generator.RangeGenerator
+		if err := col.Generator.UnmarshalFunc(&g); err != nil {
+			return fmt.Errorf("parsing range process for %s: %w", col.Name, err)
+		}
+		if err := g.Generate(t, col, files); err != nil {
+			return fmt.Errorf("running range process for %s.%s: %w", t.Name, col.Name, err)
+		}
 
-		case "lookup":
-			var g generator.LookupGenerator
-			if err := col.Generator.UnmarshalFunc(&g); err != nil {
-				return fmt.Errorf("parsing lookup process for %s: %w", col.Name, err)
-			}
-			if err := g.Generate(t, col, files); err != nil {
-				return fmt.Errorf("running lookup process for %s.%s: %w", t.Name, col.Name, err)
-			}
+	case "match":
+		var g generator.MatchGenerator
+		if err := col.Generator.UnmarshalFunc(&g); err != nil {
+			return fmt.Errorf("parsing match process for %s: %w", col.Name, err)
+		}
+		if err := g.Generate(t, col, files); err != nil {
+			return fmt.Errorf("running match process for %s.%s: %w", t.Name, col.Name, err)
+		}
 
-		case "dist":
-			var g generator.DistGenerator
-			if err := col.Generator.UnmarshalFunc(&g); err != nil {
-				return fmt.Errorf("parsing dist process for %s: %w", col.Name, err)
-			}
-			if err := g.Generate(t, col, files); err != nil {
-				return fmt.Errorf("running dist process for %s.%s: %w", t.Name, col.Name, err)
-			}
+	case "cuid2":
+		var g generator.Cuid2Generator
+		if err := col.Generator.UnmarshalFunc(&g); err != nil {
+			return fmt.Errorf("parsing cuid2 process for %s: %w", col.Name, err)
+		}
+		if err := g.Generate(t, col, files); err != nil {
+			return fmt.Errorf("running cuid2 process for %s.%s: %w", t.Name, col.Name, err)
+		}
+
+	case "rel_date", "relative_date":
+		var g generator.RelDateGenerator
+		if err := col.Generator.UnmarshalFunc(&g); err != nil {
+			return fmt.Errorf("parsing rel_date process for %s: %w", col.Name, err)
+		}
+		if err := g.Generate(t, col, files); err != nil {
+			return fmt.Errorf("running rel_date process for %s.%s: %w", t.Name, col.Name, err)
+		}
+
+	case "rand":
+		var g generator.RandGenerator
+		if err := col.Generator.UnmarshalFunc(&g); err != nil {
+			return fmt.Errorf("parsing rand process for %s: %w", col.Name, err)
+		}
+		if err := g.Generate(t, col, files); err != nil {
+			return fmt.Errorf("running rand process for %s.%s: %w", t.Name, col.Name, err)
+		}
+
+	case "expr":
+		var g generator.ExprGenerator
+		if err := col.Generator.UnmarshalFunc(&g); err != nil {
+			return fmt.Errorf("parsing expr process for %s: %w", col.Name, err)
+		}
+		if err := g.Generate(t, col, files); err != nil {
+			return fmt.Errorf("running expr process for %s.%s: %w", t.Name, col.Name, err)
+		}
+
+	case "case":
+		var g generator.CaseGenerator
+		if err := col.Generator.UnmarshalFunc(&g); err != nil {
+			return fmt.Errorf("parsing case process for %s: %w", col.Name, err)
+		}
+		if err := g.Generate(t, col, files); err != nil {
+			return fmt.Errorf("running case process for %s.%s: %w", t.Name, col.Name, err)
+		}
+
+	case "map":
+		var g generator.MapGenerator
+		if err := col.Generator.UnmarshalFunc(&g); err != nil {
+			return fmt.Errorf("parsing map process for %s: %w", col.Name, err)
+		}
+		if err := g.Generate(t, col, files); err != nil {
+			return fmt.Errorf("running map process for %s.%s: %w", t.Name, col.Name, err)
+		}
+
+	case "pick":
+		var g generator.PickGenerator
+		if err := col.Generator.UnmarshalFunc(&g); err != nil {
+			return fmt.Errorf("parsing once process for %s: %w", col.Name, err)
+		}
+		if err := g.Generate(t, col, files); err != nil {
+			return fmt.Errorf("running once process for %s.%s: %w", t.Name, col.Name, err)
+		}
+
+	case "lookup":
+		var g generator.LookupGenerator
+		if err := col.Generator.UnmarshalFunc(&g); err != nil {
+			return fmt.Errorf("parsing lookup process for %s: %w", col.Name, err)
+		}
+		if err := g.Generate(t, col, files); err != nil {
+			return fmt.Errorf("running lookup process for %s.%s: %w", t.Name, col.Name, err)
+		}
+
+	case "dist":
+		var g generator.DistGenerator
+		if err := col.Generator.UnmarshalFunc(&g); err != nil {
+			return fmt.Errorf("parsing dist process for %s: %w", col.Name, err)
+		}
+		if err := g.Generate(t, col, files); err != nil {
+			return fmt.Errorf("running dist process for %s.%s: %w", t.Name, col.Name, err)
+		}
+
+	default:
+		// fk, each and const columns have already been generated.
+		if !generator.KnownType(col.Type) {
+			return fmt.Errorf("unknown type %q for %s.%s", col.Type, t.Name, col.Name)
 		}
 	}
 
-	file, ok := files[t.Name]
-	if !ok {
-		return fmt.Errorf("missing table: %q", t.Name)
+	return nil
+}
+
+func validateDataTypes(c model.Config, tt ui.TimerFunc, files map[string]model.CSVFile) error {
+	defer tt(time.Now(), "validated data types")
+
+	for _, table := range c.Tables {
+		file, ok := files[table.Name]
+		if !ok {
+			continue
+		}
+
+		if err := validateTable(table, file, 0); err != nil {
+			return err
+		}
 	}
 
-	if len(file.UniqueColumns) > 0 {
-		file.Lines = generator.Transpose(file.Lines)
-		file.Lines = file.Unique()
-		file.Lines = generator.Transpose(file.Lines)
+	return nil
+}
+
+// validateTable checks a table's values against the data types of their
+// columns. offset is the number of rows that come before the file's first
+// row, for tables that are validated a chunk at a time.
+func validateTable(table model.Table, file model.CSVFile, offset int) error {
+	for _, col := range table.Columns {
+		if col.DataType == "" {
+			continue
+		}
+		if !col.DataType.Valid() {
+			return col.Position.Wrap(fmt.Errorf("%q is not a valid data type for %s.%s", col.DataType, table.Name, col.Name))
+		}
+
+		for i, value := range file.GetColumnValues(col.Name) {
+			if err := col.DataType.Check(value); err != nil {
+				return col.Position.Wrap(fmt.Errorf("table %q, column %q, row %d: %w", table.Name, col.Name, offset+i+1, err))
+			}
+		}
 	}
-	files[t.Name] = file
 
 	return nil
 }
@@ -409,19 +772,27 @@ func removeSuppressedColumns(c model.Config, tt ui.TimerFunc, files map[string]m
 	return nil
 }
 
-func writeFiles(outputDir string, cfs map[string]model.CSVFile, tt ui.TimerFunc) error {
-	defer tt(time.Now(), "wrote all csvs")
+func writeFiles(c model.Config, outputDir string, output model.Output, sqlOptions writer.Options, cfs map[string]model.CSVFile, tt ui.TimerFunc) error {
+	defer tt(time.Now(), "wrote all files")
 
 	if err := os.MkdirAll(outputDir, os.ModePerm); err != nil {
 		return fmt.Errorf("creating output directory: %w", err)
 	}
 
-	for name, file := range cfs {
-		if !file.Output {
+	for _, table := range c.Tables {
+		file, ok := cfs[table.Name]
+		if !ok || !file.Output {
 			continue
 		}
 
-		if err := writeFile(outputDir, name, file, tt); err != nil {
+		tableOutput := output.Override(table.Output)
+
+		opts := sqlOptions
+		opts.Empty = tableOutput.Empty
+		opts.Types = table.DataTypes()
+		opts.Table = table.Name
+
+		if err := writeFile(outputDir, table.Name, file, tableOutput.Format, opts, tt); err != nil {
 			return fmt.Errorf("writing file %q: %w", file.Name, err)
 		}
 	}
@@ -429,63 +800,362 @@ func writeFiles(outputDir string, cfs map[string]model.CSVFile, tt ui.TimerFunc)
 	return nil
 }
 
-func writeFile(outputDir, name string, cf model.CSVFile, tt ui.TimerFunc) error {
-	defer tt(time.Now(), fmt.Sprintf("wrote csv: %s", name))
+func writeFile(outputDir, name string, cf model.CSVFile, format string, opts writer.Options, tt ui.TimerFunc) error {
+	defer tt(time.Now(), fmt.Sprintf("wrote %s: %s", format, name))
 
-	fullPath := path.Join(outputDir, fmt.Sprintf("%s.csv", name))
+	fullPath := path.Join(outputDir, name+writer.Extension(format))
 	file, err := os.Create(fullPath)
 	if err != nil {
-		return fmt.Errorf("creating csv file %q: %w", name, err)
+		return fmt.Errorf("creating %s file %q: %w", format, name, err)
 	}
 	defer file.Close()
 
-	writer := csv.NewWriter(file)
-	if err = writer.Write(cf.Header); err != nil {
-		return fmt.Errorf("writing csv header for %q: %w", name, err)
+	return writeTable(file, name, cf, format, opts)
+}
+
+func writeTable(file io.Writer, name string, cf model.CSVFile, format string, opts writer.Options) error {
+	w, err := writer.New(format, file, opts)
+	if err != nil {
+		return fmt.Errorf("creating writer for %q: %w", name, err)
 	}
 
-	cf.Lines = generator.Transpose(cf.Lines)
+	if err = w.WriteHeader(cf.Header); err != nil {
+		return fmt.Errorf("writing header for %q: %w", name, err)
+	}
 
-	if err = writer.WriteAll(cf.Lines); err != nil {
-		return fmt.Errorf("writing csv lines for %q: %w", name, err)
+	if err = w.WriteRows(generator.Transpose(cf.Lines)); err != nil {
+		return fmt.Errorf("writing lines for %q: %w", name, err)
 	}
 
-	writer.Flush()
+	if err = w.Flush(); err != nil {
+		return fmt.Errorf("flushing %q: %w", name, err)
+	}
 	return nil
 }
 
-func writeImports(outputDir, name string, c model.Config, files map[string]model.CSVFile, tt ui.TimerFunc) error {
-	defer tt(time.Now(), fmt.Sprintf("wrote imports: %s", name))
+func writeInserts(outputDir, name string, c model.Config, sqlOptions writer.Options, files map[string]model.CSVFile, tt ui.TimerFunc) error {
+	defer tt(time.Now(), fmt.Sprintf("wrote inserts: %s", name))
 
-	importTmpl := template.Must(template.New("import").
-		Funcs(template.FuncMap{"join": strings.Join}).
-		Parse(`IMPORT INTO {{.Name}} (
-	{{ join .Header ", " }}
-)
-CSV DATA (
-    '.../{{.Name}}.csv'
-)
-WITH skip='1', nullif = '', allow_quoted_null;
+	if err := os.MkdirAll(outputDir, os.ModePerm); err != nil {
+		return fmt.Errorf("creating output directory: %w", err)
+	}
 
-`),
-	)
+	fullPath := path.Join(outputDir, name)
+	file, err := os.Create(fullPath)
+	if err != nil {
+		return fmt.Errorf("creating sql file %q: %w", name, err)
+	}
+	defer file.Close()
+
+	// Iterate through the tables in the config file, so the inserts are in the right order.
+	for _, table := range c.Tables {
+		csv, ok := files[table.Name]
+		if !ok || !csv.Output {
+			continue
+		}
+
+		opts := sqlOptions
+		opts.Types = table.DataTypes()
+		opts.Table = table.Name
+
+		if err := writeTable(file, table.Name, csv, writer.FormatSQL, opts); err != nil {
+			return fmt.Errorf("writing insert statements for %q: %w", table.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// streamTables generates and writes tables one at a time, rather than
+// generating every table before writing any. Once a table is written, only
+// the columns that other tables read from are kept in memory. Tables that
+// can be streamed are generated and written a chunk at a time.
+func streamTables(c model.Config, outputDir, insertsName string, output model.Output, sqlOptions writer.Options, chunkSize int, tt ui.TimerFunc, files map[string]model.CSVFile) error {
+	defer tt(time.Now(), "streamed all tables")
+
+	if chunkSize <= 0 {
+		return fmt.Errorf("chunk size must be positive")
+	}
+
+	requirements, err := generator.Requirements(c.Tables)
+	if err != nil {
+		return fmt.Errorf("finding table requirements: %w", err)
+	}
+
+	if err := os.MkdirAll(outputDir, os.ModePerm); err != nil {
+		return fmt.Errorf("creating output directory: %w", err)
+	}
+
+	var inserts io.Writer
+	if insertsName != "" {
+		file, err := os.Create(path.Join(outputDir, insertsName))
+		if err != nil {
+			return fmt.Errorf("creating sql file %q: %w", insertsName, err)
+		}
+		defer file.Close()
+		inserts = file
+	}
+
+	for _, table := range c.Tables {
+		// Work out counts that depend on the tables generated so far.
+		if err = generator.ResolveCount(&table, files); err != nil {
+			return table.Position.Wrap(fmt.Errorf("generating csv file for %q: %w", table.Name, err))
+		}
+
+		tw, err := newTableWriter(table, outputDir, inserts, output, sqlOptions)
+		if err != nil {
+			return fmt.Errorf("creating writer for %q: %w", table.Name, err)
+		}
+
+		streamable, err := generator.Streamable(table)
+		if err != nil {
+			return fmt.Errorf("checking whether %q can be streamed: %w", table.Name, err)
+		}
+
+		requirement := requirements[table.Name]
+		if streamable && !requirement.All {
+			err = streamTable(table, requirement, tw, chunkSize, tt, files)
+		} else {
+			err = generateAndWriteTable(table, requirement, tw, tt, files)
+		}
+		if err != nil {
+			tw.Close()
+			return err
+		}
+
+		if err = tw.Close(); err != nil {
+			return fmt.Errorf("writing %q: %w", table.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// streamTable generates and writes a table a chunk at a time, keeping the
+// columns that other tables read from.
+func streamTable(table model.Table, requirement generator.Requirement, tw *tableWriter, chunkSize int, tt ui.TimerFunc, files map[string]model.CSVFile) error {
+	defer tt(time.Now(), fmt.Sprintf("streamed table: %s", table.Name))
+
+	s, err := generator.NewStream(table, files)
+	if err != nil {
+		return table.Position.Wrap(fmt.Errorf("streaming %q: %w", table.Name, err))
+	}
+
+	header := s.Header()
+	kept := make([][]string, len(header))
+
+	if err = tw.WriteHeader(outputHeader(table)); err != nil {
+		return fmt.Errorf("writing header for %q: %w", table.Name, err)
+	}
+
+	for rows := 0; ; {
+		lines, err := s.Next(chunkSize)
+		if err != nil {
+			return table.Position.Wrap(fmt.Errorf("streaming %q: %w", table.Name, err))
+		}
+		if len(lines) == 0 {
+			break
+		}
+		chunk := model.CSVFile{Name: table.Name, Header: header, Lines: lines}
+
+		if err = validateTable(table, chunk, rows); err != nil {
+			return err
+		}
+
+		out, err := reorderTable(table, chunk)
+		if err != nil {
+			return err
+		}
+		if err = tw.WriteRows(generator.Transpose(out.Lines)); err != nil {
+			return fmt.Errorf("writing lines for %q: %w", table.Name, err)
+		}
+
+		for i, name := range header {
+			if lo.Contains(requirement.Columns, name) {
+				kept[i] = append(kept[i], lines[i]...)
+			}
+		}
+		rows += len(lines[0])
+	}
+
+	files[table.Name] = model.CSVFile{
+		Name:   table.Name,
+		Header: header,
+		Lines:  kept,
+		Output: !table.Suppress,
+	}
+	return nil
+}
+
+// generateAndWriteTable generates a whole table and writes it, keeping the
+// columns that other tables read from.
+func generateAndWriteTable(table model.Table, requirement generator.Requirement, tw *tableWriter, tt ui.TimerFunc, files map[string]model.CSVFile) error {
+	if err := generateTable(table, files, tt); err != nil {
+		return table.Position.Wrap(fmt.Errorf("generating csv file for %q: %w", table.Name, err))
+	}
+	file := files[table.Name]
+
+	if err := validateTable(table, file, 0); err != nil {
+		return err
+	}
+
+	out, err := reorderTable(table, file)
+	if err != nil {
+		return err
+	}
+
+	if err = tw.WriteHeader(out.Header); err != nil {
+		return fmt.Errorf("writing header for %q: %w", table.Name, err)
+	}
+	if err = tw.WriteRows(generator.Transpose(out.Lines)); err != nil {
+		return fmt.Errorf("writing lines for %q: %w", table.Name, err)
+	}
+
+	if !requirement.All {
+		lines := make([][]string, len(file.Lines))
+		for i, name := range file.Header {
+			if i < len(lines) && lo.Contains(requirement.Columns, name) {
+				lines[i] = file.Lines[i]
+			}
+		}
+		file.Lines = lines
+	}
+	files[table.Name] = file
+	return nil
+}
+
+// outputHeader returns the names of the columns that are written for a
+// table, in the order they're declared.
+func outputHeader(table model.Table) []string {
+	return lo.FilterMap(table.Columns, func(c model.Column, _ int) (string, bool) {
+		return c.Name, !c.Suppress
+	})
+}
+
+// tableWriter writes a table's rows to its output file and, optionally, as
+// insert statements. Nothing is written for suppressed tables.
+type tableWriter struct {
+	file    *os.File
+	writers []writer.Writer
+}
+
+func newTableWriter(table model.Table, outputDir string, inserts io.Writer, output model.Output, sqlOptions writer.Options) (*tableWriter, error) {
+	var tw tableWriter
+	if table.Suppress {
+		return &tw, nil
+	}
+
+	tableOutput := output.Override(table.Output)
+
+	opts := sqlOptions
+	opts.Empty = tableOutput.Empty
+	opts.Types = table.DataTypes()
+	opts.Table = table.Name
+
+	file, err := os.Create(path.Join(outputDir, table.Name+writer.Extension(tableOutput.Format)))
+	if err != nil {
+		return nil, fmt.Errorf("creating %s file %q: %w", tableOutput.Format, table.Name, err)
+	}
+	tw.file = file
+
+	w, err := writer.New(tableOutput.Format, file, opts)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	tw.writers = append(tw.writers, w)
+
+	if inserts != nil {
+		w, err := writer.New(writer.FormatSQL, inserts, opts)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		tw.writers = append(tw.writers, w)
+	}
+
+	return &tw, nil
+}
+
+func (tw *tableWriter) WriteHeader(header []string) error {
+	for _, w := range tw.writers {
+		if err := w.WriteHeader(header); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tw *tableWriter) WriteRows(rows [][]string) error {
+	for _, w := range tw.writers {
+		if err := w.WriteRows(rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close flushes the table's writers and closes its output file.
+func (tw *tableWriter) Close() error {
+	for _, w := range tw.writers {
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	if tw.file == nil {
+		return nil
+	}
+	return tw.file.Close()
+}
+
+func writeDDL(outputDir, name string, c model.Config, d dialect.Dialect, tt ui.TimerFunc) error {
+	defer tt(time.Now(), fmt.Sprintf("wrote ddl: %s", name))
+
+	tables, err := schema.FromConfig(c)
+	if err != nil {
+		return fmt.Errorf("building schema: %w", err)
+	}
+
+	fullPath := path.Join(outputDir, name)
+	file, err := os.Create(fullPath)
+	if err != nil {
+		return fmt.Errorf("creating sql file %q: %w", name, err)
+	}
+	defer file.Close()
+
+	return writer.WriteDDL(file, d, tables)
+}
+
+func writeImports(outputDir, name string, c model.Config, output model.Output, opts writer.ImportOptions, files map[string]model.CSVFile, tt ui.TimerFunc) error {
+	defer tt(time.Now(), fmt.Sprintf("wrote imports: %s", name))
+
+	opts.Dir = outputDir
 
 	fullPath := path.Join(outputDir, name)
 	file, err := os.Create(fullPath)
 	if err != nil {
-		return fmt.Errorf("creating csv file %q: %w", name, err)
+		return fmt.Errorf("creating sql file %q: %w", name, err)
 	}
 	defer file.Close()
 
+	w, err := writer.NewImportWriter(file, opts)
+	if err != nil {
+		return fmt.Errorf("creating import writer: %w", err)
+	}
+
 	// Iterate through the tables in the config file, so the imports are in the right order.
 	for _, table := range c.Tables {
-		csv := files[table.Name]
-		if !csv.Output {
+		csv, ok := files[table.Name]
+		if !ok || !csv.Output {
 			continue
 		}
 
-		if err := importTmpl.Execute(file, csv); err != nil {
-			return fmt.Errorf("writing import statement for %q: %w", name, err)
+		if format := output.Override(table.Output).Format; format != writer.FormatCSV && format != "" {
+			return fmt.Errorf("importing %q: only csv files can be imported, not %s", table.Name, format)
+		}
+
+		if err := w.WriteTable(table.Name, outputHeader(table)); err != nil {
+			return fmt.Errorf("writing import statement for %q: %w", table.Name, err)
 		}
 	}
 
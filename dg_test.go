@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codingconcepts/dg/internal/pkg/generator"
+	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/codingconcepts/dg/internal/pkg/random"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateTablesWorkers(t *testing.T) {
+	y := `
+tables:
+  - name: person
+    count: 10
+    columns:
+      - name: id
+        type: inc
+        processor:
+          start: 1
+      - name: name
+        type: gen
+        processor:
+          value: ${first_name}
+
+  - name: pet
+    count: 10
+    columns:
+      - name: owner
+        type: expr
+        processor:
+          expression: get_record("per" + "son", 1).name
+
+  - name: toy
+    count: len(get_column("pe" + "t", "owner"))
+    where: get_record("per" + "son", 0).id != ""
+    columns:
+      - name: name
+        type: gen
+        processor:
+          value: ${noun}
+`
+
+	c, err := model.LoadConfig(strings.NewReader(y), ".")
+	if err != nil {
+		t.Fatalf("error loading config: %v", err)
+	}
+	if c.Tables, err = generator.SortTables(c.Tables); err != nil {
+		t.Fatalf("error ordering tables: %v", err)
+	}
+
+	generate := func(workers int) map[string]model.CSVFile {
+		random.Seed(3)
+		files := map[string]model.CSVFile{}
+		if err := generateTables(c, workers, func(time.Time, string) {}, files); err != nil {
+			t.Fatalf("error generating tables with %d workers: %v", workers, err)
+		}
+		return files
+	}
+
+	exp := generate(1)
+	assert.Len(t, exp["toy"].Lines[0], 10)
+
+	for i := 0; i < 10; i++ {
+		act := generate(4)
+		for _, table := range c.Tables {
+			assert.Equal(t, exp[table.Name].Lines, act[table.Name].Lines, table.Name)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package ddl
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/codingconcepts/dg/internal/pkg/generator"
+	"github.com/codingconcepts/dg/internal/pkg/graph"
+	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/nrednav/cuid2"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the YAML representation of a model.Config, whose processors are
+// plain values rather than model.RawMessages.
+type Config struct {
+	Tables []ConfigTable `yaml:"tables"`
+}
+
+// ConfigTable is the YAML representation of a model.Table.
+type ConfigTable struct {
+	Name    string         `yaml:"name"`
+	Count   int            `yaml:"count,omitempty"`
+	Columns []ConfigColumn `yaml:"columns"`
+}
+
+// ConfigColumn is the YAML representation of a model.Column.
+type ConfigColumn struct {
+	Name       string         `yaml:"name"`
+	Type       string         `yaml:"type"`
+	DataType   model.DataType `yaml:"data_type,omitempty"`
+	PrimaryKey bool           `yaml:"primary_key,omitempty"`
+	Processor  Processor      `yaml:"processor"`
+}
+
+// Processor holds the fields of a column's processor, which are written in
+// the order given.
+type Processor []Field
+
+// Field is a single processor field.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// MarshalYAML writes a processor as a mapping.
+func (p Processor) MarshalYAML() (any, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, f := range p {
+		var value yaml.Node
+		if err := value.Encode(f.Value); err != nil {
+			return nil, fmt.Errorf("encoding %s: %w", f.Key, err)
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: f.Key}, &value)
+	}
+	return node, nil
+}
+
+// Default ranges for generated values.
+const (
+	numberLow  = 1
+	numberHigh = 1000
+	dateLow    = "2020-01-01"
+	dateHigh   = "2025-12-31"
+)
+
+// ToConfig builds a config that generates count rows for each table, ordered
+// so that tables come after the tables they reference.
+//
+// Foreign keys become ref columns (or each columns, if a table's primary key
+// is made up entirely of foreign keys, as in many-to-many resolver tables),
+// serial and integer primary keys become inc columns, uuid columns become
+// gen columns and enums and CHECK (column IN (...)) constraints become set
+// columns. Other columns are generated with a processor suited to their type.
+func ToConfig(tables []Table, count int) (Config, error) {
+	tables, err := sortTables(tables)
+	if err != nil {
+		return Config{}, err
+	}
+
+	known := lo.SliceToMap(tables, func(t Table) (string, bool) {
+		return t.Name, true
+	})
+
+	var c Config
+	for _, t := range tables {
+		references := map[string]ForeignKey{}
+		for _, fk := range t.ForeignKeys {
+			if len(fk.Columns) == 1 && len(fk.RefColumns) == 1 && known[fk.RefTable] {
+				references[fk.Columns[0]] = fk
+			}
+		}
+
+		// Resolver tables get a row for each combination of the rows they
+		// reference.
+		resolver := len(t.PrimaryKey) > 1 && lo.EveryBy(t.PrimaryKey, func(column string) bool {
+			_, ok := references[column]
+			return ok
+		})
+
+		table := ConfigTable{Name: t.Name}
+		if !resolver {
+			table.Count = count
+		}
+
+		for _, col := range t.Columns {
+			column := ConfigColumn{Name: col.Name}
+			if col.DataType != model.DataTypeString {
+				column.DataType = col.DataType
+			}
+
+			// Tables fall back to a column called id as their primary key.
+			pk := lo.Contains(t.PrimaryKey, col.Name)
+			column.PrimaryKey = pk && !(len(t.PrimaryKey) == 1 && col.Name == "id")
+
+			if fk, ok := references[col.Name]; ok {
+				column.Type = "ref"
+				if resolver && pk {
+					column.Type = "each"
+				}
+				column.Processor = Processor{{"table", fk.RefTable}, {"column", fk.RefColumns[0]}}
+			} else {
+				column.Type, column.Processor = processor(col, pk)
+			}
+
+			table.Columns = append(table.Columns, column)
+		}
+
+		c.Tables = append(c.Tables, table)
+	}
+
+	return c, nil
+}
+
+// WriteConfig writes a config as YAML.
+func WriteConfig(w io.Writer, c Config) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(c); err != nil {
+		return fmt.Errorf("encoding config: %w", err)
+	}
+	return enc.Close()
+}
+
+// processor returns the type and processor of a column that doesn't
+// reference another table.
+func processor(c Column, pk bool) (string, Processor) {
+	switch {
+	case len(c.Values) > 0:
+		return "set", Processor{{"values", c.Values}}
+
+	case c.DataType == model.DataTypeInt && (c.Serial || pk):
+		return "inc", Processor{{"start", 1}}
+	}
+
+	switch c.DataType {
+	case model.DataTypeUUID:
+		return "gen", Processor{{"value", "${uuid}"}}
+
+	case model.DataTypeInt:
+		return "rand", Processor{
+			{"type", "int"},
+			{"low", fmt.Sprint(numberLow)},
+			{"high", fmt.Sprint(numberHigh)},
+		}
+
+	case model.DataTypeDecimal:
+		scale := c.Scale
+		if c.Length == 0 {
+			scale = 2
+		}
+
+		high := float64(numberHigh)
+		if c.Length > 0 {
+			high = math.Min(high, math.Pow10(c.Length-scale)-1)
+		}
+
+		return "rand", Processor{
+			{"type", "float64"},
+			{"low", "0"},
+			{"high", fmt.Sprint(high)},
+			{"format", fmt.Sprintf("%%.%df", scale)},
+		}
+
+	case model.DataTypeFloat:
+		return "rand", Processor{
+			{"type", "float64"},
+			{"low", "0"},
+			{"high", fmt.Sprint(numberHigh)},
+		}
+
+	case model.DataTypeBool:
+		return "set", Processor{{"values", []string{"true", "false"}}}
+
+	case model.DataTypeDate:
+		return "rand", Processor{
+			{"type", "date"},
+			{"low", dateLow},
+			{"high", dateHigh},
+		}
+
+	case model.DataTypeTimestamp:
+		return "rand", Processor{
+			{"type", "date"},
+			{"low", dateLow + "T00:00:00Z"},
+			{"high", dateHigh + "T23:59:59Z"},
+			{"format", "2006-01-02T15:04:05Z07:00"},
+		}
+
+	case model.DataTypeJSON:
+		return "gen", Processor{{"value", `{"value": "${word}"}`}}
+	}
+
+	return stringProcessor(c, pk)
+}
+
+// namePlaceholders map common column name suffixes to the placeholders that
+// generate them, for columns whose names aren't placeholders themselves.
+var namePlaceholders = []struct {
+	suffix      string
+	placeholder string
+}{
+	{suffix: "email", placeholder: "${email}"},
+	{suffix: "phone", placeholder: "${phone}"},
+	{suffix: "url", placeholder: "${url}"},
+	{suffix: "city", placeholder: "${city}"},
+	{suffix: "country", placeholder: "${country}"},
+	{suffix: "name", placeholder: "${name}"},
+}
+
+// stringProcessor returns the type and processor of a string column, using
+// a placeholder that matches the column's name where there is one (e.g.
+// ${email} for an email column).
+func stringProcessor(c Column, pk bool) (string, Processor) {
+	switch {
+	case pk && (c.Length == 0 || c.Length >= cuid2.MinIdLength):
+		length := cuid2.DefaultIdLength
+		if c.Length > 0 {
+			length = min(c.Length, cuid2.MaxIdLength)
+		}
+		return "cuid2", Processor{{"length", length}}
+
+	case c.Fixed && c.Length > 0:
+		return "gen", Processor{{"pattern", fmt.Sprintf("[A-Z]{%d}", c.Length)}}
+
+	case c.Length > 0 && c.Length < 10:
+		return "gen", Processor{{"pattern", fmt.Sprintf("[a-z]{1,%d}", c.Length)}}
+	}
+
+	name := strings.ToLower(c.Name)
+	placeholder := "${" + name + "}"
+	if !generator.IsPlaceholder(placeholder) {
+		placeholder = "${word}"
+		for _, np := range namePlaceholders {
+			if strings.HasSuffix(name, np.suffix) {
+				placeholder = np.placeholder
+				break
+			}
+		}
+	}
+
+	return "gen", Processor{{"value", placeholder}}
+}
+
+func sortTables(tables []Table) ([]Table, error) {
+	sorted, err := graph.SortBy(tables, func(t Table) string {
+		return t.Name
+	}, func(t Table) []string {
+		return lo.Map(t.ForeignKeys, func(fk ForeignKey, _ int) string {
+			return fk.RefTable
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ordering tables: %w", err)
+	}
+
+	return sorted, nil
+}
@@ -0,0 +1,140 @@
+package ddl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToConfig(t *testing.T) {
+	tables, err := Parse(strings.NewReader(`
+CREATE TABLE person_event (
+  person_id UUID REFERENCES person (id),
+  event_id INT REFERENCES event (id),
+  PRIMARY KEY (person_id, event_id)
+);
+
+CREATE TABLE person (
+  id UUID PRIMARY KEY,
+  email VARCHAR(255),
+  country_code CHAR(2),
+  price DECIMAL(5, 2),
+  active BOOLEAN,
+  born DATE
+);
+
+CREATE TABLE event (
+  code VARCHAR(12) PRIMARY KEY,
+  id SERIAL,
+  owner_id UUID REFERENCES person (id),
+  kind TEXT CHECK (kind IN ('online', 'offline'))
+);`))
+	assert.NoError(t, err)
+
+	c, err := ToConfig(tables, 10)
+	assert.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, WriteConfig(buf, c))
+
+	exp := `tables:
+  - name: person
+    count: 10
+    columns:
+      - name: id
+        type: gen
+        data_type: uuid
+        processor:
+          value: ${uuid}
+      - name: email
+        type: gen
+        processor:
+          value: ${email}
+      - name: country_code
+        type: gen
+        processor:
+          pattern: '[A-Z]{2}'
+      - name: price
+        type: rand
+        data_type: decimal
+        processor:
+          type: float64
+          low: "0"
+          high: "999"
+          format: '%.2f'
+      - name: active
+        type: set
+        data_type: bool
+        processor:
+          values:
+            - "true"
+            - "false"
+      - name: born
+        type: rand
+        data_type: date
+        processor:
+          type: date
+          low: "2020-01-01"
+          high: "2025-12-31"
+  - name: event
+    count: 10
+    columns:
+      - name: code
+        type: cuid2
+        primary_key: true
+        processor:
+          length: 12
+      - name: id
+        type: inc
+        data_type: int
+        processor:
+          start: 1
+      - name: owner_id
+        type: ref
+        data_type: uuid
+        processor:
+          table: person
+          column: id
+      - name: kind
+        type: set
+        processor:
+          values:
+            - online
+            - offline
+  - name: person_event
+    columns:
+      - name: person_id
+        type: each
+        data_type: uuid
+        primary_key: true
+        processor:
+          table: person
+          column: id
+      - name: event_id
+        type: each
+        data_type: int
+        primary_key: true
+        processor:
+          table: event
+          column: id
+`
+	assert.Equal(t, exp, buf.String())
+
+	// The config should be loadable by dg.
+	loaded, err := model.LoadConfig(buf, ".")
+	assert.NoError(t, err)
+	assert.Len(t, loaded.Tables, 3)
+}
+
+func TestToConfigCycle(t *testing.T) {
+	tables := []Table{
+		{Name: "a", ForeignKeys: []ForeignKey{{Columns: []string{"b_id"}, RefTable: "b", RefColumns: []string{"id"}}}},
+		{Name: "b", ForeignKeys: []ForeignKey{{Columns: []string{"a_id"}, RefTable: "a", RefColumns: []string{"id"}}}},
+	}
+
+	_, err := ToConfig(tables, 10)
+	assert.EqualError(t, err, "ordering tables: dependency cycle detected: a -> b -> a")
+}
@@ -0,0 +1,246 @@
+package ddl
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type kind int
+
+const (
+	kindWord kind = iota
+	kindQuoted
+	kindString
+	kindPunct
+)
+
+// token is a word, quoted identifier, string literal or punctuation mark.
+// The values of quoted identifiers and strings are unquoted.
+type token struct {
+	kind  kind
+	value string
+}
+
+// tokenize splits SQL into tokens, discarding whitespace and comments.
+func tokenize(sql string) ([]token, error) {
+	var tokens []token
+	runes := []rune(sql)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			end := strings.Index(string(runes[i+2:]), "*/")
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += 2 + len([]rune(string(runes[i+2:])[:end])) + 2
+
+		case r == '\'' || r == '"' || r == '`' || r == '[':
+			closing := map[rune]rune{'\'': '\'', '"': '"', '`': '`', '[': ']'}[r]
+			value, n, err := quoted(runes[i+1:], closing)
+			if err != nil {
+				return nil, err
+			}
+
+			k := kindQuoted
+			if r == '\'' {
+				k = kindString
+			}
+			tokens = append(tokens, token{kind: k, value: value})
+			i += n + 1
+
+		case r == '$' && dollarTag(runes[i:]) != "":
+			tag := dollarTag(runes[i:])
+			rest := string(runes[i+len([]rune(tag)):])
+			end := strings.Index(rest, tag)
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated string %s", tag)
+			}
+			tokens = append(tokens, token{kind: kindString, value: rest[:end]})
+			i += len([]rune(tag + rest[:end] + tag))
+
+		case isWordRune(r):
+			start := i
+			for i < len(runes) && (isWordRune(runes[i]) || runes[i] == '$') {
+				i++
+			}
+			tokens = append(tokens, token{kind: kindWord, value: string(runes[start:i])})
+
+		default:
+			tokens = append(tokens, token{kind: kindPunct, value: string(r)})
+			i++
+		}
+	}
+
+	return tokens, nil
+}
+
+// quoted returns the value of a quoted identifier or string, where the
+// closing quote is escaped by doubling it, and the number of runes consumed
+// (including the closing quote).
+func quoted(runes []rune, closing rune) (string, int, error) {
+	var b strings.Builder
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != closing {
+			b.WriteRune(runes[i])
+			continue
+		}
+		if i+1 < len(runes) && runes[i+1] == closing {
+			b.WriteRune(closing)
+			i++
+			continue
+		}
+		return b.String(), i + 1, nil
+	}
+	return "", 0, fmt.Errorf("unterminated quote %q", closing)
+}
+
+// dollarTag returns the opening tag of a dollar-quoted string (e.g. $$ or
+// $body$), or an empty string if the runes don't start with one.
+func dollarTag(runes []rune) string {
+	for i := 1; i < len(runes); i++ {
+		if runes[i] == '$' {
+			return string(runes[:i+1])
+		}
+		if !isWordRune(runes[i]) || unicode.IsDigit(runes[i]) {
+			return ""
+		}
+	}
+	return ""
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// stream reads through a statement's tokens.
+type stream struct {
+	tokens []token
+	pos    int
+}
+
+func (s *stream) done() bool {
+	return s.pos >= len(s.tokens)
+}
+
+// next returns the next token, or an empty token at the end of the stream.
+func (s *stream) next() token {
+	if s.done() {
+		return token{}
+	}
+	s.pos++
+	return s.tokens[s.pos-1]
+}
+
+// peek returns true if the next token is the given punctuation mark.
+func (s *stream) peek(punct string) bool {
+	return !s.done() && s.tokens[s.pos].kind == kindPunct && s.tokens[s.pos].value == punct
+}
+
+// peekKeyword returns true if the next token is one of the given keywords.
+func (s *stream) peekKeyword(keywords ...string) bool {
+	if s.done() || s.tokens[s.pos].kind != kindWord {
+		return false
+	}
+	for _, k := range keywords {
+		if strings.EqualFold(s.tokens[s.pos].value, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// keyword consumes the given sequence of keywords, if it's next.
+func (s *stream) keyword(keywords ...string) bool {
+	if s.pos+len(keywords) > len(s.tokens) {
+		return false
+	}
+	for i, k := range keywords {
+		t := s.tokens[s.pos+i]
+		if t.kind != kindWord || !strings.EqualFold(t.value, k) {
+			return false
+		}
+	}
+	s.pos += len(keywords)
+	return true
+}
+
+// name consumes a (possibly qualified) name and returns its last part.
+func (s *stream) name() string {
+	var name string
+	for {
+		t := s.next()
+		if t.kind != kindWord && t.kind != kindQuoted {
+			return name
+		}
+		name = t.value
+
+		if !s.peek(".") {
+			return name
+		}
+		s.next()
+	}
+}
+
+// group consumes a parenthesised group and returns the tokens inside it, or
+// nil if the next token doesn't open a group.
+func (s *stream) group() []token {
+	if !s.peek("(") {
+		return nil
+	}
+
+	start := s.pos + 1
+	for depth := 0; !s.done(); {
+		switch t := s.next(); {
+		case t.kind == kindPunct && t.value == "(":
+			depth++
+		case t.kind == kindPunct && t.value == ")":
+			if depth--; depth == 0 {
+				return s.tokens[start : s.pos-1]
+			}
+		}
+	}
+	return s.tokens[start:]
+}
+
+// split splits tokens on a punctuation mark outside of parentheses, dropping
+// empty parts.
+func split(tokens []token, sep string) [][]token {
+	var parts [][]token
+	depth, start := 0, 0
+
+	for i, t := range tokens {
+		if t.kind != kindPunct {
+			continue
+		}
+		switch t.value {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		case sep:
+			if depth == 0 {
+				if i > start {
+					parts = append(parts, tokens[start:i])
+				}
+				start = i + 1
+			}
+		}
+	}
+
+	if len(tokens) > start {
+		parts = append(parts, tokens[start:])
+	}
+	return parts
+}
@@ -0,0 +1,324 @@
+package ddl
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/samber/lo"
+)
+
+// Table is a table parsed from a CREATE TABLE statement.
+type Table struct {
+	Name        string
+	Columns     []Column
+	PrimaryKey  []string
+	ForeignKeys []ForeignKey
+}
+
+// Column is a column parsed from a CREATE TABLE statement.
+type Column struct {
+	Name     string
+	SQLType  string
+	DataType model.DataType
+
+	// Length is the maximum length of string columns, or the precision of
+	// decimal columns (0 if unbounded).
+	Length int
+	Scale  int
+
+	// Fixed is true for fixed length strings (e.g. CHAR(3)).
+	Fixed bool
+
+	// Serial is true for columns populated by the database (e.g. SERIAL,
+	// IDENTITY or AUTO_INCREMENT columns).
+	Serial bool
+
+	// Values holds the allowed values of enum columns or columns with a
+	// CHECK (column IN (...)) constraint.
+	Values []string
+}
+
+// ForeignKey is a foreign key constraint parsed from a CREATE TABLE
+// statement.
+type ForeignKey struct {
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+}
+
+// Parse reads the CREATE TABLE (and CREATE TYPE ... AS ENUM) statements of a
+// SQL script, ignoring any other statements.
+func Parse(r io.Reader) ([]Table, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading ddl: %w", err)
+	}
+
+	tokens, err := tokenize(string(b))
+	if err != nil {
+		return nil, fmt.Errorf("tokenizing ddl: %w", err)
+	}
+
+	p := parser{enums: map[string][]string{}}
+	for _, stmt := range split(tokens, ";") {
+		if err = p.statement(stmt); err != nil {
+			return nil, err
+		}
+	}
+
+	p.resolveReferences()
+	return p.tables, nil
+}
+
+type parser struct {
+	tables []Table
+	enums  map[string][]string
+}
+
+func (p *parser) statement(stmt []token) error {
+	s := stream{tokens: stmt}
+	if !s.keyword("CREATE") {
+		return nil
+	}
+	s.keyword("OR", "REPLACE")
+	for s.keyword("TEMP") || s.keyword("TEMPORARY") || s.keyword("UNLOGGED") {
+	}
+
+	switch {
+	case s.keyword("TYPE"):
+		name := s.name()
+		if s.keyword("AS", "ENUM") {
+			p.enums[strings.ToLower(name)] = literals(s.group())
+		}
+		return nil
+
+	case s.keyword("TABLE"):
+		s.keyword("IF", "NOT", "EXISTS")
+		t := Table{Name: s.name()}
+		if t.Name == "" || !s.peek("(") {
+			return nil
+		}
+
+		for _, element := range split(s.group(), ",") {
+			if err := p.element(&t, element); err != nil {
+				return fmt.Errorf("parsing table %q: %w", t.Name, err)
+			}
+		}
+
+		p.tables = append(p.tables, t)
+	}
+
+	return nil
+}
+
+// element parses a column definition or table constraint.
+func (p *parser) element(t *Table, element []token) error {
+	s := stream{tokens: element}
+	if s.keyword("CONSTRAINT") {
+		s.next()
+	}
+
+	switch {
+	case s.keyword("PRIMARY", "KEY"):
+		t.PrimaryKey = names(s.group())
+	case s.keyword("FOREIGN", "KEY"):
+		fk := ForeignKey{Columns: names(s.group())}
+		if s.keyword("REFERENCES") {
+			fk.RefTable = s.name()
+			fk.RefColumns = names(s.group())
+		}
+		t.ForeignKeys = append(t.ForeignKeys, fk)
+	case s.keyword("CHECK"):
+		if column, values, ok := checkIn(s.group()); ok {
+			setValues(t, column, values)
+		}
+	case s.keyword("UNIQUE"), s.keyword("KEY"), s.keyword("INDEX"), s.keyword("EXCLUDE"), s.keyword("FULLTEXT"):
+	default:
+		return p.column(t, &s)
+	}
+
+	return nil
+}
+
+// column parses a column definition and any inline constraints.
+func (p *parser) column(t *Table, s *stream) error {
+	name := s.next()
+	if name.kind != kindWord && name.kind != kindQuoted || name.value == "" {
+		return fmt.Errorf("missing column name")
+	}
+	c := Column{Name: name.value}
+
+	var words []string
+	var args []token
+	for !s.done() && !s.peekKeyword(columnConstraints...) {
+		if s.peek("(") {
+			args = s.group()
+			continue
+		}
+
+		// Keep only the last part of qualified type names (e.g. public.mood).
+		if s.peek(".") && len(words) > 0 {
+			s.next()
+			words = words[:len(words)-1]
+			continue
+		}
+		words = append(words, strings.ToUpper(s.next().value))
+	}
+	c.SQLType = strings.Join(words, " ")
+	p.columnType(&c, args)
+
+	for !s.done() {
+		switch {
+		case s.keyword("PRIMARY", "KEY"):
+			t.PrimaryKey = []string{c.Name}
+		case s.keyword("REFERENCES"):
+			fk := ForeignKey{Columns: []string{c.Name}, RefTable: s.name()}
+			if s.peek("(") {
+				fk.RefColumns = names(s.group())
+			}
+			t.ForeignKeys = append(t.ForeignKeys, fk)
+		case s.keyword("CHECK"):
+			if column, values, ok := checkIn(s.group()); ok && strings.EqualFold(column, c.Name) {
+				c.Values = values
+			}
+		case s.keyword("DEFAULT"):
+			// Skip the default's expression, noting sequence defaults.
+			for !s.done() && !s.peekKeyword(columnConstraints...) {
+				if v := s.next(); strings.EqualFold(v.value, "nextval") || strings.EqualFold(v.value, "unique_rowid") {
+					c.Serial = true
+				}
+				if s.peek("(") {
+					s.group()
+				}
+			}
+		case s.keyword("AUTO_INCREMENT"), s.keyword("AUTOINCREMENT"), s.keyword("IDENTITY"), s.keyword("GENERATED"):
+			c.Serial = true
+			s.group()
+		default:
+			if s.next(); s.peek("(") {
+				s.group()
+			}
+		}
+	}
+
+	t.Columns = append(t.Columns, c)
+	return nil
+}
+
+// columnConstraints are the keywords that end a column's type.
+var columnConstraints = []string{
+	"PRIMARY", "NOT", "NULL", "DEFAULT", "REFERENCES", "UNIQUE", "CHECK",
+	"CONSTRAINT", "AUTO_INCREMENT", "AUTOINCREMENT", "GENERATED", "IDENTITY",
+	"COLLATE", "COMMENT", "ON", "AS",
+}
+
+// columnType sets a column's data type from its SQL type.
+func (p *parser) columnType(c *Column, args []token) {
+	base, _, _ := strings.Cut(c.SQLType, " ")
+	base = strings.TrimSuffix(base, "[]")
+	numbers := lo.FilterMap(args, func(t token, _ int) (int, bool) {
+		n, err := strconv.Atoi(t.value)
+		return n, err == nil && t.kind == kindWord
+	})
+
+	switch base {
+	case "SERIAL", "SERIAL2", "SERIAL4", "SERIAL8", "SMALLSERIAL", "BIGSERIAL":
+		c.DataType, c.Serial = model.DataTypeInt, true
+	case "TINYINT":
+		c.DataType = model.DataTypeInt
+		if len(numbers) == 1 && numbers[0] == 1 {
+			c.DataType = model.DataTypeBool
+		}
+	case "INT", "INTEGER", "INT2", "INT4", "INT8", "SMALLINT", "MEDIUMINT", "BIGINT":
+		c.DataType = model.DataTypeInt
+	case "DECIMAL", "NUMERIC", "DEC", "MONEY", "SMALLMONEY":
+		c.DataType = model.DataTypeDecimal
+		if len(numbers) > 0 {
+			c.Length = numbers[0]
+		}
+		if len(numbers) > 1 {
+			c.Scale = numbers[1]
+		}
+	case "REAL", "FLOAT", "FLOAT4", "FLOAT8", "DOUBLE":
+		c.DataType = model.DataTypeFloat
+	case "BOOL", "BOOLEAN", "BIT":
+		c.DataType = model.DataTypeBool
+	case "DATE":
+		c.DataType = model.DataTypeDate
+	case "TIMESTAMP", "TIMESTAMPTZ", "DATETIME", "DATETIME2", "SMALLDATETIME", "DATETIMEOFFSET":
+		c.DataType = model.DataTypeTimestamp
+	case "UUID", "UNIQUEIDENTIFIER":
+		c.DataType = model.DataTypeUUID
+	case "JSON", "JSONB":
+		c.DataType = model.DataTypeJSON
+	case "ENUM":
+		c.DataType = model.DataTypeString
+		c.Values = literals(args)
+	default:
+		c.DataType = model.DataTypeString
+		if values, ok := p.enums[strings.ToLower(base)]; ok {
+			c.Values = values
+		}
+		if len(numbers) > 0 {
+			c.Length = numbers[0]
+		}
+		c.Fixed = lo.Contains([]string{"CHAR", "NCHAR", "CHARACTER"}, base) && !strings.Contains(c.SQLType, "VARYING")
+	}
+}
+
+// resolveReferences fills in the referenced columns of foreign keys that
+// reference a table's primary key implicitly.
+func (p *parser) resolveReferences() {
+	pks := map[string][]string{}
+	for _, t := range p.tables {
+		pks[t.Name] = t.PrimaryKey
+	}
+
+	for i, t := range p.tables {
+		for j, fk := range t.ForeignKeys {
+			if len(fk.RefColumns) == 0 {
+				p.tables[i].ForeignKeys[j].RefColumns = pks[fk.RefTable]
+			}
+		}
+	}
+}
+
+// checkIn returns the column and values of a "column IN (...)" check.
+func checkIn(tokens []token) (string, []string, bool) {
+	s := stream{tokens: tokens}
+	column := s.next()
+	if column.kind != kindWord && column.kind != kindQuoted {
+		return "", nil, false
+	}
+	if !s.keyword("IN") || !s.peek("(") {
+		return "", nil, false
+	}
+
+	values := literals(s.group())
+	return column.value, values, s.done() && len(values) > 0
+}
+
+func setValues(t *Table, column string, values []string) {
+	for i, c := range t.Columns {
+		if strings.EqualFold(c.Name, column) {
+			t.Columns[i].Values = values
+		}
+	}
+}
+
+// names returns the identifiers in a list of tokens.
+func names(tokens []token) []string {
+	return lo.FilterMap(tokens, func(t token, _ int) (string, bool) {
+		return t.value, t.kind == kindWord || t.kind == kindQuoted
+	})
+}
+
+// literals returns the string literals in a list of tokens.
+func literals(tokens []token) []string {
+	return lo.FilterMap(tokens, func(t token, _ int) (string, bool) {
+		return t.value, t.kind == kindString
+	})
+}
@@ -0,0 +1,131 @@
+package ddl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	sql := `
+-- Types and tables.
+CREATE TYPE public.person_type AS ENUM ('admin', 'regular', 'read-only');
+
+CREATE TABLE IF NOT EXISTS public."person" (
+  "id" UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  "full_name" VARCHAR(100) NOT NULL,
+  "user_type" person_type NOT NULL,
+  "balance" DECIMAL(10, 2) DEFAULT 0.00,
+  "status" TEXT CHECK (status IN ('active', 'it''s complicated')),
+  "country" CHAR(2),
+  "created_at" TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+/* A table with
+   table constraints. */
+CREATE TABLE ` + "`person_event`" + ` (
+  person_id UUID NOT NULL,
+  event_id INT NOT NULL REFERENCES event ON DELETE CASCADE,
+  CONSTRAINT pk PRIMARY KEY (person_id, event_id),
+  CONSTRAINT fk_person FOREIGN KEY (person_id) REFERENCES person (id),
+  KEY idx_event (event_id)
+) ENGINE=InnoDB;
+
+CREATE TABLE [event] (
+  [id] INT IDENTITY(1,1) PRIMARY KEY,
+  [active] BIT,
+  [kind] ENUM('a', 'b'),
+  [score] DOUBLE PRECISION
+);
+
+CREATE INDEX idx ON person (full_name);
+
+CREATE FUNCTION f() RETURNS void AS $$ BEGIN; END; $$ LANGUAGE plpgsql;
+`
+
+	tables, err := Parse(strings.NewReader(sql))
+	assert.NoError(t, err)
+
+	exp := []Table{
+		{
+			Name: "person",
+			Columns: []Column{
+				{Name: "id", SQLType: "UUID", DataType: model.DataTypeUUID},
+				{Name: "full_name", SQLType: "VARCHAR", DataType: model.DataTypeString, Length: 100},
+				{Name: "user_type", SQLType: "PERSON_TYPE", DataType: model.DataTypeString, Values: []string{"admin", "regular", "read-only"}},
+				{Name: "balance", SQLType: "DECIMAL", DataType: model.DataTypeDecimal, Length: 10, Scale: 2},
+				{Name: "status", SQLType: "TEXT", DataType: model.DataTypeString, Values: []string{"active", "it's complicated"}},
+				{Name: "country", SQLType: "CHAR", DataType: model.DataTypeString, Length: 2, Fixed: true},
+				{Name: "created_at", SQLType: "TIMESTAMP WITH TIME ZONE", DataType: model.DataTypeTimestamp},
+			},
+			PrimaryKey: []string{"id"},
+		},
+		{
+			Name: "person_event",
+			Columns: []Column{
+				{Name: "person_id", SQLType: "UUID", DataType: model.DataTypeUUID},
+				{Name: "event_id", SQLType: "INT", DataType: model.DataTypeInt},
+			},
+			PrimaryKey: []string{"person_id", "event_id"},
+			ForeignKeys: []ForeignKey{
+				{Columns: []string{"event_id"}, RefTable: "event", RefColumns: []string{"id"}},
+				{Columns: []string{"person_id"}, RefTable: "person", RefColumns: []string{"id"}},
+			},
+		},
+		{
+			Name: "event",
+			Columns: []Column{
+				{Name: "id", SQLType: "INT", DataType: model.DataTypeInt, Serial: true},
+				{Name: "active", SQLType: "BIT", DataType: model.DataTypeBool},
+				{Name: "kind", SQLType: "ENUM", DataType: model.DataTypeString, Values: []string{"a", "b"}},
+				{Name: "score", SQLType: "DOUBLE PRECISION", DataType: model.DataTypeFloat},
+			},
+			PrimaryKey: []string{"id"},
+		},
+	}
+
+	assert.Equal(t, exp, tables)
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		sql  string
+		exp  string
+	}{
+		{name: "unterminated string", sql: "CREATE TYPE t AS ENUM ('a);", exp: `tokenizing ddl: unterminated quote '\''`},
+		{name: "unterminated comment", sql: "/* CREATE TABLE t (id INT);", exp: "tokenizing ddl: unterminated comment"},
+		{name: "missing column name", sql: "CREATE TABLE t (id INT, ());", exp: `parsing table "t": missing column name`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(c.sql))
+			assert.EqualError(t, err, c.exp)
+		})
+	}
+}
+
+func TestSerialColumns(t *testing.T) {
+	tables, err := Parse(strings.NewReader(`
+CREATE TABLE t (
+  a SERIAL,
+  b BIGINT GENERATED ALWAYS AS IDENTITY,
+  c INT AUTO_INCREMENT,
+  d INTEGER PRIMARY KEY AUTOINCREMENT,
+  e INT DEFAULT nextval('t_e_seq'),
+  f INT8 DEFAULT unique_rowid(),
+  g INT DEFAULT 1,
+  h TINYINT(1)
+);`))
+	assert.NoError(t, err)
+
+	serial := lo.Map(tables[0].Columns, func(c Column, _ int) bool {
+		return c.Serial
+	})
+	assert.Equal(t, []bool{true, true, true, true, true, true, false, false}, serial)
+	assert.Equal(t, model.DataTypeBool, tables[0].Columns[7].DataType)
+}
@@ -0,0 +1,192 @@
+package dialect
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/samber/lo"
+)
+
+// Supported dialects.
+const (
+	CockroachDB = "cockroachdb"
+	DuckDB      = "duckdb"
+	PostgreSQL  = "postgres"
+	MySQL       = "mysql"
+	SQLite      = "sqlite"
+	SQLServer   = "sqlserver"
+)
+
+// Dialect captures the differences in SQL syntax between databases.
+type Dialect struct {
+	Name string
+
+	// MaxBatchSize is the maximum number of rows a database accepts in a
+	// single INSERT statement (0 for no limit).
+	MaxBatchSize int
+
+	quoteOpen   string
+	quoteClose  string
+	boolTrue    string
+	boolFalse   string
+	unicode     bool
+	escapeSlash bool
+
+	types   map[model.DataType]string
+	varchar string
+
+	// keyLength bounds string columns that are part of a key, for databases
+	// that can't index unbounded strings.
+	keyLength int
+}
+
+var dialects = map[string]Dialect{
+	CockroachDB: {
+		Name: CockroachDB, quoteOpen: `"`, quoteClose: `"`, boolTrue: "true", boolFalse: "false",
+		varchar: "VARCHAR(%d)",
+		types: map[model.DataType]string{
+			model.DataTypeInt: "INT8", model.DataTypeFloat: "FLOAT8", model.DataTypeDecimal: "DECIMAL",
+			model.DataTypeBool: "BOOL", model.DataTypeDate: "DATE", model.DataTypeTimestamp: "TIMESTAMP",
+			model.DataTypeUUID: "UUID", model.DataTypeJSON: "JSONB", model.DataTypeString: "STRING",
+		},
+	},
+	DuckDB: {
+		Name: DuckDB, quoteOpen: `"`, quoteClose: `"`, boolTrue: "true", boolFalse: "false",
+		varchar: "VARCHAR(%d)",
+		types: map[model.DataType]string{
+			model.DataTypeInt: "BIGINT", model.DataTypeFloat: "DOUBLE", model.DataTypeDecimal: "DECIMAL(18,6)",
+			model.DataTypeBool: "BOOLEAN", model.DataTypeDate: "DATE", model.DataTypeTimestamp: "TIMESTAMP",
+			model.DataTypeUUID: "UUID", model.DataTypeJSON: "JSON", model.DataTypeString: "VARCHAR",
+		},
+	},
+	PostgreSQL: {
+		Name: PostgreSQL, quoteOpen: `"`, quoteClose: `"`, boolTrue: "true", boolFalse: "false",
+		varchar: "VARCHAR(%d)",
+		types: map[model.DataType]string{
+			model.DataTypeInt: "BIGINT", model.DataTypeFloat: "DOUBLE PRECISION", model.DataTypeDecimal: "NUMERIC",
+			model.DataTypeBool: "BOOLEAN", model.DataTypeDate: "DATE", model.DataTypeTimestamp: "TIMESTAMP",
+			model.DataTypeUUID: "UUID", model.DataTypeJSON: "JSONB", model.DataTypeString: "TEXT",
+		},
+	},
+	MySQL: {
+		Name: MySQL, quoteOpen: "`", quoteClose: "`", boolTrue: "TRUE", boolFalse: "FALSE", escapeSlash: true,
+		varchar: "VARCHAR(%d)", keyLength: 255,
+		types: map[model.DataType]string{
+			model.DataTypeInt: "BIGINT", model.DataTypeFloat: "DOUBLE", model.DataTypeDecimal: "DECIMAL(20,6)",
+			model.DataTypeBool: "BOOLEAN", model.DataTypeDate: "DATE", model.DataTypeTimestamp: "DATETIME",
+			model.DataTypeUUID: "CHAR(36)", model.DataTypeJSON: "JSON", model.DataTypeString: "TEXT",
+		},
+	},
+	SQLite: {
+		Name: SQLite, quoteOpen: `"`, quoteClose: `"`, boolTrue: "1", boolFalse: "0",
+		varchar: "VARCHAR(%d)",
+		types: map[model.DataType]string{
+			model.DataTypeInt: "INTEGER", model.DataTypeFloat: "REAL", model.DataTypeDecimal: "NUMERIC",
+			model.DataTypeBool: "BOOLEAN", model.DataTypeDate: "DATE", model.DataTypeTimestamp: "TIMESTAMP",
+			model.DataTypeUUID: "TEXT", model.DataTypeJSON: "TEXT", model.DataTypeString: "TEXT",
+		},
+	},
+	SQLServer: {
+		Name: SQLServer, quoteOpen: "[", quoteClose: "]", boolTrue: "1", boolFalse: "0", unicode: true, MaxBatchSize: 1000,
+		varchar: "NVARCHAR(%d)", keyLength: 450,
+		types: map[model.DataType]string{
+			model.DataTypeInt: "BIGINT", model.DataTypeFloat: "FLOAT", model.DataTypeDecimal: "DECIMAL(20,6)",
+			model.DataTypeBool: "BIT", model.DataTypeDate: "DATE", model.DataTypeTimestamp: "DATETIME2",
+			model.DataTypeUUID: "UNIQUEIDENTIFIER", model.DataTypeJSON: "NVARCHAR(MAX)", model.DataTypeString: "NVARCHAR(MAX)",
+		},
+	},
+}
+
+// Get returns the dialect with the given name.
+func Get(name string) (Dialect, error) {
+	d, ok := dialects[name]
+	if !ok {
+		names := lo.Keys(dialects)
+		sort.Strings(names)
+		return Dialect{}, fmt.Errorf("%q is not a valid dialect (expected one of %s)", name, strings.Join(names, ", "))
+	}
+	return d, nil
+}
+
+// QuoteIdent quotes a table or column name.
+func (d Dialect) QuoteIdent(name string) string {
+	escaped := strings.ReplaceAll(name, d.quoteClose, d.quoteClose+d.quoteClose)
+	return d.quoteOpen + escaped + d.quoteClose
+}
+
+// QuoteString quotes a value as a string literal.
+func (d Dialect) QuoteString(value string) string {
+	if d.escapeSlash {
+		value = strings.ReplaceAll(value, `\`, `\\`)
+	}
+	value = strings.ReplaceAll(value, "'", "''")
+
+	if d.unicode {
+		return "N'" + value + "'"
+	}
+	return "'" + value + "'"
+}
+
+// ColumnType returns the column type for a data type. Strings with a
+// non-zero length are bounded, as are strings in a key (primary or foreign)
+// for databases that can't index unbounded strings.
+func (d Dialect) ColumnType(dt model.DataType, length int, key bool) string {
+	if dt == "" {
+		dt = model.DataTypeString
+	}
+
+	if dt == model.DataTypeString {
+		if length == 0 && key {
+			length = d.keyLength
+		}
+		if length > 0 {
+			return fmt.Sprintf(d.varchar, length)
+		}
+	}
+
+	return d.types[dt]
+}
+
+// Literal returns a value as a SQL literal, according to its data type.
+// Empty values are written as NULL, which mirrors how empty values are
+// treated when importing CSVs.
+func (d Dialect) Literal(value string, dt model.DataType) (string, error) {
+	if value == "" {
+		return "NULL", nil
+	}
+
+	switch dt {
+	case model.DataTypeInt, model.DataTypeDecimal:
+		if err := dt.Check(value); err != nil {
+			return "", err
+		}
+		return value, nil
+
+	case model.DataTypeFloat:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", fmt.Errorf("%q is not a valid %s", value, dt)
+		}
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return d.QuoteString(value), nil
+		}
+		return value, nil
+
+	case model.DataTypeBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("%q is not a valid %s", value, dt)
+		}
+		if b {
+			return d.boolTrue, nil
+		}
+		return d.boolFalse, nil
+
+	default:
+		return d.QuoteString(value), nil
+	}
+}
@@ -0,0 +1,143 @@
+package dialect
+
+import (
+	"testing"
+
+	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGet(t *testing.T) {
+	for _, name := range []string{CockroachDB, DuckDB, PostgreSQL, MySQL, SQLite, SQLServer} {
+		d, err := Get(name)
+		assert.NoError(t, err)
+		assert.Equal(t, name, d.Name)
+	}
+
+	_, err := Get("oracle")
+	assert.EqualError(t, err, `"oracle" is not a valid dialect (expected one of cockroachdb, duckdb, mysql, postgres, sqlite, sqlserver)`)
+}
+
+func TestQuoteIdent(t *testing.T) {
+	cases := []struct {
+		dialect string
+		exp     string
+	}{
+		{dialect: CockroachDB, exp: `"my ""odd"" [name]` + "`" + `"`},
+		{dialect: PostgreSQL, exp: `"my ""odd"" [name]` + "`" + `"`},
+		{dialect: SQLite, exp: `"my ""odd"" [name]` + "`" + `"`},
+		{dialect: MySQL, exp: "`my \"odd\" [name]``" + "`"},
+		{dialect: SQLServer, exp: `[my "odd" [name]]` + "`]"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.dialect, func(t *testing.T) {
+			d, err := Get(c.dialect)
+			assert.NoError(t, err)
+
+			assert.Equal(t, c.exp, d.QuoteIdent(`my "odd" [name]`+"`"))
+		})
+	}
+}
+
+func TestQuoteString(t *testing.T) {
+	cases := []struct {
+		dialect string
+		exp     string
+	}{
+		{dialect: CockroachDB, exp: `'it''s a\b'`},
+		{dialect: PostgreSQL, exp: `'it''s a\b'`},
+		{dialect: SQLite, exp: `'it''s a\b'`},
+		{dialect: MySQL, exp: `'it''s a\\b'`},
+		{dialect: SQLServer, exp: `N'it''s a\b'`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.dialect, func(t *testing.T) {
+			d, err := Get(c.dialect)
+			assert.NoError(t, err)
+
+			assert.Equal(t, c.exp, d.QuoteString(`it's a\b`))
+		})
+	}
+}
+
+func TestLiteral(t *testing.T) {
+	pg, _ := Get(PostgreSQL)
+	ss, _ := Get(SQLServer)
+
+	cases := []struct {
+		name     string
+		dialect  Dialect
+		value    string
+		dataType model.DataType
+		exp      string
+		expErr   bool
+	}{
+		{name: "empty", dialect: pg, value: "", dataType: model.DataTypeInt, exp: "NULL"},
+		{name: "untyped", dialect: pg, value: "42", exp: "'42'"},
+		{name: "int", dialect: pg, value: "42", dataType: model.DataTypeInt, exp: "42"},
+		{name: "invalid int", dialect: pg, value: "a", dataType: model.DataTypeInt, expErr: true},
+		{name: "decimal", dialect: pg, value: "-1.50", dataType: model.DataTypeDecimal, exp: "-1.50"},
+		{name: "float", dialect: pg, value: "1.5e3", dataType: model.DataTypeFloat, exp: "1.5e3"},
+		{name: "float nan", dialect: pg, value: "NaN", dataType: model.DataTypeFloat, exp: "'NaN'"},
+		{name: "bool", dialect: pg, value: "t", dataType: model.DataTypeBool, exp: "true"},
+		{name: "bool sqlserver", dialect: ss, value: "false", dataType: model.DataTypeBool, exp: "0"},
+		{name: "invalid bool", dialect: pg, value: "yes", dataType: model.DataTypeBool, expErr: true},
+		{name: "date", dialect: pg, value: "2024-01-31", dataType: model.DataTypeDate, exp: "'2024-01-31'"},
+		{name: "json", dialect: ss, value: `{"a": "b's"}`, dataType: model.DataTypeJSON, exp: `N'{"a": "b''s"}'`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			act, err := c.dialect.Literal(c.value, c.dataType)
+			if c.expErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, c.exp, act)
+		})
+	}
+}
+
+func TestColumnType(t *testing.T) {
+	pg, _ := Get(PostgreSQL)
+	ss, _ := Get(SQLServer)
+
+	cases := []struct {
+		name     string
+		dialect  Dialect
+		dataType model.DataType
+		length   int
+		key      bool
+		exp      string
+	}{
+		{name: "int", dialect: pg, dataType: model.DataTypeInt, exp: "BIGINT"},
+		{name: "untyped", dialect: pg, exp: "TEXT"},
+		{name: "bounded string", dialect: pg, dataType: model.DataTypeString, length: 10, exp: "VARCHAR(10)"},
+		{name: "key string", dialect: pg, dataType: model.DataTypeString, key: true, exp: "TEXT"},
+		{name: "sqlserver bool", dialect: ss, dataType: model.DataTypeBool, exp: "BIT"},
+		{name: "sqlserver bounded string", dialect: ss, dataType: model.DataTypeString, length: 10, exp: "NVARCHAR(10)"},
+		{name: "sqlserver key string", dialect: ss, dataType: model.DataTypeString, key: true, exp: "NVARCHAR(450)"},
+		{name: "sqlserver key uuid", dialect: ss, dataType: model.DataTypeUUID, key: true, exp: "UNIQUEIDENTIFIER"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.exp, c.dialect.ColumnType(c.dataType, c.length, c.key))
+		})
+	}
+}
+
+func TestColumnTypeCoverage(t *testing.T) {
+	for name, d := range dialects {
+		for _, dt := range []model.DataType{
+			model.DataTypeInt, model.DataTypeFloat, model.DataTypeDecimal,
+			model.DataTypeBool, model.DataTypeDate, model.DataTypeTimestamp,
+			model.DataTypeUUID, model.DataTypeJSON, model.DataTypeString,
+		} {
+			assert.NotEmpty(t, d.ColumnType(dt, 0, false), "%s %s", name, dt)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package generator
 
 import (
+	"math"
+	"math/rand/v2"
+
 	"github.com/samber/lo"
 )
 
@@ -36,6 +39,65 @@ func CartesianProduct(a ...[]string) [][]string {
 	return result
 }
 
+// product enumerates the Cartesian product of a variable number of arrays by
+// index, without building it. Combinations are in the same order as those
+// returned by CartesianProduct, with the first array varying fastest.
+type product struct {
+	arrays [][]string
+
+	// size is the number of combinations, or -1 if there are more than an
+	// int can hold.
+	size int
+}
+
+func newProduct(a ...[]string) product {
+	p := product{arrays: a, size: 1}
+	for _, arr := range a {
+		if len(arr) == 0 {
+			p.size = 0
+			return p
+		}
+		if p.size > 0 && p.size > math.MaxInt/len(arr) {
+			p.size = -1
+		}
+		if p.size > 0 {
+			p.size *= len(arr)
+		}
+	}
+	return p
+}
+
+// value returns the value of the given array in the combination at index.
+func (p product) value(index, array int) string {
+	for _, arr := range p.arrays[:array] {
+		index /= len(arr)
+	}
+	arr := p.arrays[array]
+	return arr[index%len(arr)]
+}
+
+// sampleIndexes returns n distinct indexes between 0 and size, in a random
+// order. It shuffles only as much of the range as it needs, so memory use
+// depends on n rather than size.
+func sampleIndexes(r *rand.Rand, size, n int) []int {
+	swapped := map[int]int{}
+	at := func(i int) int {
+		if v, ok := swapped[i]; ok {
+			return v
+		}
+		return i
+	}
+
+	indexes := make([]int, n)
+	for i := range indexes {
+		j := i + r.IntN(size-i)
+		indexes[i] = at(j)
+		swapped[j] = at(i)
+		delete(swapped, i)
+	}
+	return indexes
+}
+
 // Transpose a multi-dimensional array.
 func Transpose(m [][]string) [][]string {
 	max := lo.MaxBy(m, func(a, b []string) bool {
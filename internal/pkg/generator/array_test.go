@@ -1,9 +1,13 @@
 package generator
 
 import (
+	"math"
+	"math/rand/v2"
+	"slices"
 	"strings"
 	"testing"
 
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -243,3 +247,55 @@ func BenchmarkTranspose(b *testing.B) {
 		})
 	}
 }
+
+func TestProduct(t *testing.T) {
+	cases := []struct {
+		name   string
+		arrays [][]string
+		size   int
+	}{
+		{name: "one array", arrays: [][]string{{"a", "b", "c"}}, size: 3},
+		{name: "two arrays", arrays: [][]string{{"a", "b"}, {"c", "d", "e"}}, size: 6},
+		{name: "three arrays", arrays: [][]string{{"a", "b"}, {"c"}, {"d", "e"}}, size: 4},
+		{name: "empty array", arrays: [][]string{{"a", "b"}, {}}, size: 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p := newProduct(c.arrays...)
+			assert.Equal(t, c.size, p.size)
+
+			exp := CartesianProduct(c.arrays...)
+			act := make([][]string, p.size)
+			for i := range act {
+				for a := range c.arrays {
+					act[i] = append(act[i], p.value(i, a))
+				}
+			}
+			assert.Equal(t, exp, act)
+		})
+	}
+}
+
+func TestProductOverflow(t *testing.T) {
+	large := make([]string, 1<<21)
+	p := newProduct(large, large, large)
+	assert.Equal(t, -1, p.size)
+}
+
+func TestSampleIndexes(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+
+	// Sampling the whole range returns every index once.
+	all := sampleIndexes(r, 100, 100)
+	sorted := slices.Clone(all)
+	slices.Sort(sorted)
+	for i, v := range sorted {
+		assert.Equal(t, i, v)
+	}
+	assert.NotEqual(t, sorted, all)
+
+	// Samples of large ranges don't repeat.
+	some := sampleIndexes(r, math.MaxInt, 1000)
+	assert.Len(t, lo.Uniq(some), 1000)
+}
@@ -0,0 +1,151 @@
+package generator
+
+import (
+	"math/rand/v2"
+	"strconv"
+	"sync"
+
+	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/codingconcepts/dg/internal/pkg/random"
+)
+
+// blockRows is the number of rows in each block of a column's values. Each
+// block draws from its own random source, so blocks can be generated in any
+// order, or at the same time, and still produce the same values.
+const blockRows = 10000
+
+// workers is the number of blocks of a column that are generated at a time.
+var workers = 1
+
+// SetWorkers sets the number of blocks of a column's rows that are generated
+// at a time. Output doesn't depend on the number of workers.
+func SetWorkers(n int) {
+	workers = max(n, 1)
+}
+
+// scale is the factor that fk repeat counts, and the number of combinations
+// of each tables without a count, are multiplied by.
+var scale = 1.0
+
+// SetScale sets the factor that fk repeat counts, and the number of
+// combinations of each tables without a count, are multiplied by. Tables
+// that are fixed aren't scaled.
+func SetScale(factor float64) {
+	scale = factor
+}
+
+// blockFunc returns a function that generates the values of a block of a
+// column's rows in turn, starting at the given row and drawing from r.
+type blockFunc func(r *rand.Rand, row int) (valueFunc, error)
+
+// blockRand returns the random source for a block of a column's rows. The
+// first block draws from the column's random source, so columns that fit in
+// one block don't depend on the block size.
+func blockRand(t model.Table, c model.Column, block int) *rand.Rand {
+	if block == 0 {
+		return columnRand(t, c)
+	}
+	return random.Derive(t.Name, c.Name, strconv.Itoa(block))
+}
+
+// generateBlocks generates count values for a column, a block at a time.
+func generateBlocks(t model.Table, c model.Column, count int, values blockFunc) ([]string, error) {
+	return runBlocks(t, c, count, count, func(r *rand.Rand, row, n int) ([]string, error) {
+		next, err := values(r, row)
+		if err != nil {
+			return nil, err
+		}
+
+		lines := make([]string, n)
+		for i := range lines {
+			lines[i] = next()
+		}
+		return lines, nil
+	})
+}
+
+// runBlocks splits a column's rows into blocks and runs them across workers,
+// returning the first count values that the blocks produce, in order. Blocks
+// can produce more or fewer values than they have rows. Errors from blocks
+// whose values aren't needed are ignored, as they would never have been run
+// by a single worker.
+func runBlocks(t model.Table, c model.Column, rows, count int, block func(r *rand.Rand, row, n int) ([]string, error)) ([]string, error) {
+	blocks := (rows + blockRows - 1) / blockRows
+	results := make([][]string, blocks)
+	errs := make([]error, blocks)
+
+	run := func(i int) {
+		row := i * blockRows
+		results[i], errs[i] = block(blockRand(t, c, i), row, min(blockRows, rows-row))
+	}
+
+	if workers == 1 || blocks == 1 {
+		for i, total := 0, 0; i < blocks && total < count; i++ {
+			run(i)
+			total += len(results[i])
+		}
+	} else {
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, workers)
+		for i := 0; i < blocks; i++ {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(i int) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				run(i)
+			}(i)
+		}
+		wg.Wait()
+	}
+
+	lines := make([]string, 0, count)
+	for i := range results {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		lines = append(lines, results[i]...)
+		if len(lines) >= count {
+			return lines[:count], nil
+		}
+	}
+	return lines, nil
+}
+
+// sequence generates a column's values in turn, a block at a time, producing
+// the same values as generateBlocks.
+type sequence struct {
+	table  model.Table
+	column model.Column
+	blocks blockFunc
+
+	row  int
+	next valueFunc
+}
+
+// newSequence returns a sequence of a column's values, preparing its first
+// block so that any errors are returned straight away.
+func newSequence(t model.Table, c model.Column, blocks blockFunc) (*sequence, error) {
+	next, err := blocks(blockRand(t, c, 0), 0)
+	if err != nil {
+		return nil, err
+	}
+	return &sequence{table: t, column: c, blocks: blocks, next: next}, nil
+}
+
+// values returns the next n values of the sequence.
+func (s *sequence) values(n int) ([]string, error) {
+	lines := make([]string, n)
+	for i := range lines {
+		if s.row > 0 && s.row%blockRows == 0 {
+			next, err := s.blocks(blockRand(s.table, s.column, s.row/blockRows), s.row)
+			if err != nil {
+				return nil, err
+			}
+			s.next = next
+		}
+		lines[i] = s.next()
+		s.row++
+	}
+	return lines, nil
+}
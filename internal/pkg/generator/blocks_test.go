@@ -0,0 +1,102 @@
+package generator
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/codingconcepts/dg/internal/pkg/random"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateBlocks(t *testing.T) {
+	random.Seed(42)
+	defer SetWorkers(1)
+
+	table := model.Table{Name: "person"}
+	column := model.Column{Name: "age"}
+	values := func(r *rand.Rand, row int) (valueFunc, error) {
+		return func() string {
+			row++
+			return fmt.Sprintf("%d:%d", row, r.IntN(100))
+		}, nil
+	}
+
+	count := blockRows*3 + 1
+
+	SetWorkers(1)
+	sequential, err := generateBlocks(table, column, count, values)
+	assert.NoError(t, err)
+	assert.Len(t, sequential, count)
+
+	SetWorkers(4)
+	parallel, err := generateBlocks(table, column, count, values)
+	assert.NoError(t, err)
+	assert.Equal(t, sequential, parallel)
+
+	// The first block draws from the column's random source.
+	r := columnRand(table, column)
+	assert.Equal(t, fmt.Sprintf("1:%d", r.IntN(100)), sequential[0])
+
+	// Blocks start at their first row.
+	assert.True(t, strings.HasPrefix(sequential[blockRows], fmt.Sprintf("%d:", blockRows+1)))
+}
+
+func TestRunBlocks(t *testing.T) {
+	defer SetWorkers(1)
+
+	table := model.Table{Name: "person"}
+	column := model.Column{Name: "pets"}
+
+	// Each row produces two values, so only the first half of the rows'
+	// blocks are needed, and errors from the rest are ignored.
+	block := func(r *rand.Rand, row, n int) ([]string, error) {
+		if row >= blockRows*2 {
+			return nil, fmt.Errorf("block %d shouldn't be needed", row/blockRows)
+		}
+
+		var lines []string
+		for i := row; i < row+n; i++ {
+			lines = append(lines, strconv.Itoa(i), strconv.Itoa(i))
+		}
+		return lines, nil
+	}
+
+	for _, workers := range []int{1, 4} {
+		t.Run(strconv.Itoa(workers), func(t *testing.T) {
+			SetWorkers(workers)
+
+			lines, err := runBlocks(table, column, blockRows*4, blockRows*4, block)
+			assert.NoError(t, err)
+			assert.Len(t, lines, blockRows*4)
+			assert.Equal(t, []string{"0", "0", "1", "1"}, lines[:4])
+			assert.Equal(t, strconv.Itoa(blockRows*2-1), lines[len(lines)-1])
+		})
+	}
+}
+
+func TestRunBlocksError(t *testing.T) {
+	defer SetWorkers(1)
+
+	table := model.Table{Name: "person"}
+	column := model.Column{Name: "age"}
+
+	block := func(r *rand.Rand, row, n int) ([]string, error) {
+		if row > 0 {
+			return nil, fmt.Errorf("block %d failed", row/blockRows)
+		}
+		return make([]string, n), nil
+	}
+
+	for _, workers := range []int{1, 4} {
+		t.Run(strconv.Itoa(workers), func(t *testing.T) {
+			SetWorkers(workers)
+
+			_, err := runBlocks(table, column, blockRows*3, blockRows*3, block)
+			assert.EqualError(t, err, "block 1 failed")
+		})
+	}
+}
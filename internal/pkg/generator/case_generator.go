@@ -26,11 +26,18 @@ func (g CaseGenerator) Generate(t model.Table, c model.Column, files map[string]
 			return len(a) > len(b)
 		}))
 	}
+	r := columnRand(t, c)
+
+	// Each condition has its own format, so gets its own context.
+	contexts := lo.Map(g, func(cond CaseCondition, _ int) *ExprContext {
+		return &ExprContext{Files: files, Format: cond.Format, Rand: r}
+	})
+
 	var lines []string
 	for i := 0; i < t.Count; i++ {
-		for _, cond := range g {
-			ec := &ExprContext{Files: files, Format: cond.Format}
-			record := model.GetRecord(t.Name, i, files)
+		record := model.GetRecord(t.Name, i, files)
+		for j, cond := range g {
+			ec := contexts[j]
 			env := ec.makeEnv()
 			if err := ec.mergeEnv(env, record); err != nil {
 				return err
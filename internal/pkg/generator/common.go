@@ -2,8 +2,10 @@ package generator
 
 import (
 	"fmt"
+	"math/rand/v2"
 
 	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/codingconcepts/dg/internal/pkg/random"
 )
 
 // AddTable adds a column to a table in the given files map.
@@ -52,3 +54,10 @@ func formatValue(fp FormatterProcessor, value any) string {
 		return fmt.Sprintf("%v", value)
 	}
 }
+
+// columnRand returns the random source for a column. It's derived from the
+// seed and the table and column names, so changing one column in a config
+// doesn't change the values generated for any other.
+func columnRand(t model.Table, c model.Column) *rand.Rand {
+	return random.Derive(t.Name, c.Name)
+}
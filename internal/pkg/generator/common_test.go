@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/codingconcepts/dg/internal/pkg/random"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -103,3 +104,16 @@ func TestFormatValue(t *testing.T) {
 		})
 	}
 }
+
+func TestColumnRand(t *testing.T) {
+	random.Seed(42)
+
+	person := model.Table{Name: "person"}
+	pet := model.Table{Name: "pet"}
+	name := model.Column{Name: "name"}
+	age := model.Column{Name: "age"}
+
+	assert.Equal(t, columnRand(person, name).Int64(), columnRand(person, name).Int64())
+	assert.NotEqual(t, columnRand(person, name).Int64(), columnRand(person, age).Int64())
+	assert.NotEqual(t, columnRand(person, name).Int64(), columnRand(pet, name).Int64())
+}
@@ -24,10 +24,10 @@ func (g ConstGenerator) Generate(t model.Table, files map[string]model.CSVFile)
 	for _, c := range cols {
 		var cg ConstGenerator
 		if err := c.Generator.UnmarshalFunc(&cg); err != nil {
-			return fmt.Errorf("parsing const process for %s.%s: %w", t.Name, c.Name, err)
+			return c.Position.Wrap(fmt.Errorf("parsing const process for %s.%s: %w", t.Name, c.Name, err))
 		}
 		if err := cg.generate(t, c, files); err != nil {
-			return fmt.Errorf("generating const columns: %w", err)
+			return c.Position.Wrap(fmt.Errorf("generating const columns: %w", err))
 		}
 	}
 
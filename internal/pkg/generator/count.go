@@ -0,0 +1,52 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/codingconcepts/dg/internal/pkg/random"
+)
+
+// ResolveCount sets the count of a table whose count is an expression or a
+// range, which must be done once the tables that the table reads from have
+// been generated. Expressions are evaluated with the functions available to
+// column expressions (e.g. get_column), and ranges are picked from with the
+// table's own random source.
+func ResolveCount(t *model.Table, files map[string]model.CSVFile) error {
+	switch {
+	case t.CountExpression != "":
+		ec := &ExprContext{Files: files, Rand: random.Derive(t.Name)}
+		output, err := ec.evaluate(t.CountExpression, ec.makeEnv())
+		if err != nil {
+			return fmt.Errorf("evaluating count: %w", err)
+		}
+
+		var count int
+		switch v := output.(type) {
+		case int:
+			count = v
+		case float64:
+			count = int(math.Round(v))
+		default:
+			return fmt.Errorf("count expression %q returned %v (%T), expected a number", t.CountExpression, output, output)
+		}
+		if count < 0 {
+			return fmt.Errorf("count expression %q returned %d, which is negative", t.CountExpression, count)
+		}
+		t.Count = count
+
+	case t.CountRange != nil:
+		r := *t.CountRange
+		if r.Min < 0 || r.Min > r.Max {
+			return fmt.Errorf("invalid count range (min %d, max %d)", r.Min, r.Max)
+		}
+		t.Count = r.Min + random.Derive(t.Name).IntN(r.Max-r.Min+1)
+
+	default:
+		return nil
+	}
+
+	t.CountExpression, t.CountRange = "", nil
+	return nil
+}
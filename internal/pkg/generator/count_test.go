@@ -0,0 +1,108 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveCount(t *testing.T) {
+	files := map[string]model.CSVFile{
+		"person": {
+			Name:   "person",
+			Header: []string{"id"},
+			Lines:  [][]string{{"1", "2", "3", "4"}},
+		},
+	}
+
+	cases := []struct {
+		name     string
+		table    model.Table
+		expCount int
+		expErr   string
+	}{
+		{
+			name:     "number",
+			table:    model.Table{Name: "pet", Count: 10},
+			expCount: 10,
+		},
+		{
+			name:     "expression",
+			table:    model.Table{Name: "pet", CountExpression: `len(get_column("person", "id")) * 3`},
+			expCount: 12,
+		},
+		{
+			name:     "expression with a fraction",
+			table:    model.Table{Name: "pet", CountExpression: `len(get_column("person", "id")) * 0.6`},
+			expCount: 2,
+		},
+		{
+			name:     "expression with vars",
+			table:    model.Table{Name: "pet", CountExpression: `vars.pets`},
+			expCount: 7,
+		},
+		{
+			name:   "expression that isn't a number",
+			table:  model.Table{Name: "pet", CountExpression: `"many"`},
+			expErr: `count expression "\"many\"" returned many (string), expected a number`,
+		},
+		{
+			name:   "negative expression",
+			table:  model.Table{Name: "pet", CountExpression: `-1`},
+			expErr: `count expression "-1" returned -1, which is negative`,
+		},
+		{
+			name:     "range with one value",
+			table:    model.Table{Name: "pet", CountRange: &model.CountRange{Min: 5, Max: 5}},
+			expCount: 5,
+		},
+		{
+			name:   "invalid range",
+			table:  model.Table{Name: "pet", CountRange: &model.CountRange{Min: 5, Max: 1}},
+			expErr: "invalid count range (min 5, max 1)",
+		},
+	}
+
+	SetVars(map[string]any{"pets": 7})
+	defer SetVars(nil)
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			table := c.table
+			err := ResolveCount(&table, files)
+			if c.expErr != "" {
+				assert.EqualError(t, err, c.expErr)
+				return
+			}
+			if err != nil {
+				t.Fatalf("error resolving count: %v", err)
+			}
+
+			assert.Equal(t, c.expCount, table.Count)
+			assert.Empty(t, table.CountExpression)
+			assert.Nil(t, table.CountRange)
+		})
+	}
+}
+
+func TestResolveCountRange(t *testing.T) {
+	counts := map[int]bool{}
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		table := model.Table{Name: name, CountRange: &model.CountRange{Min: 1, Max: 3}}
+		if err := ResolveCount(&table, nil); err != nil {
+			t.Fatalf("error resolving count: %v", err)
+		}
+		assert.GreaterOrEqual(t, table.Count, 1)
+		assert.LessOrEqual(t, table.Count, 3)
+		counts[table.Count] = true
+
+		// The same table always gets the same count.
+		again := model.Table{Name: name, CountRange: &model.CountRange{Min: 1, Max: 3}}
+		if err := ResolveCount(&again, nil); err != nil {
+			t.Fatalf("error resolving count: %v", err)
+		}
+		assert.Equal(t, table.Count, again.Count)
+	}
+	assert.Greater(t, len(counts), 1)
+}
@@ -2,10 +2,14 @@ package generator
 
 import (
 	"fmt"
+	"math/big"
+	"math/rand/v2"
+	"strconv"
 
 	"github.com/codingconcepts/dg/internal/pkg/model"
 	"github.com/nrednav/cuid2"
 	"github.com/samber/lo"
+	"golang.org/x/crypto/sha3"
 )
 
 type Cuid2Generator struct {
@@ -13,9 +17,6 @@ type Cuid2Generator struct {
 }
 
 func (g Cuid2Generator) Generate(t model.Table, c model.Column, files map[string]model.CSVFile) error {
-	if g.Length <= 0 {
-		return fmt.Errorf("invalid length provided for cuid2 generator")
-	}
 	count := len(lo.MaxBy(files[t.Name].Lines, func(a, b []string) bool {
 		return len(a) > len(b)
 	}))
@@ -24,16 +25,50 @@ func (g Cuid2Generator) Generate(t model.Table, c model.Column, files map[string
 		count = t.Count
 	}
 
-	generate, err := cuid2.Init(cuid2.WithLength(g.Length))
+	lines, err := generateBlocks(t, c, count, g.values)
 	if err != nil {
-		return fmt.Errorf("failed to initialize cuid2 generator: %w", err)
-	}
-
-	var lines []string
-	for i := 0; i < count; i++ {
-		lines = append(lines, generate())
+		return err
 	}
 
 	AddTable(t, c.Name, lines, files)
 	return nil
 }
+
+// values returns a function that generates a block of the column's values in
+// turn.
+func (g Cuid2Generator) values(r *rand.Rand, _ int) (valueFunc, error) {
+	if g.Length < cuid2.MinIdLength || g.Length > cuid2.MaxIdLength {
+		return nil, fmt.Errorf("invalid length provided for cuid2 generator")
+	}
+
+	counter := r.Int64N(cuid2.MaxSessionCount)
+	fingerprint := cuid2Hash(cuid2Entropy(r, cuid2.MaxIdLength))
+
+	return func() string {
+		counter++
+		return g.generate(r, counter, fingerprint)
+	}, nil
+}
+
+// generate follows the cuid2 construction (a random letter followed by a
+// hash of entropy, a counter and a fingerprint) but omits the wall-clock
+// component, so that seeded runs are reproducible.
+func (g Cuid2Generator) generate(r *rand.Rand, counter int64, fingerprint string) string {
+	letter := string(rune('a' + r.IntN(26)))
+	input := cuid2Entropy(r, g.Length) + strconv.FormatInt(counter, 36) + fingerprint
+
+	return letter + cuid2Hash(input)[1:g.Length]
+}
+
+func cuid2Entropy(r *rand.Rand, length int) string {
+	entropy := make([]byte, length)
+	for i := range entropy {
+		entropy[i] = strconv.FormatInt(int64(r.IntN(36)), 36)[0]
+	}
+	return string(entropy)
+}
+
+func cuid2Hash(input string) string {
+	digest := sha3.Sum512([]byte(input))
+	return new(big.Int).SetBytes(digest[:]).Text(36)[1:]
+}
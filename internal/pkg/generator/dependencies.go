@@ -0,0 +1,678 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/codingconcepts/dg/internal/pkg/graph"
+	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/expr-lang/expr/ast"
+	"github.com/expr-lang/expr/parser"
+	"github.com/samber/lo"
+)
+
+// Reference identifies a column in another table whose values a column is
+// drawn from.
+type Reference struct {
+	Table  string
+	Column string
+}
+
+// ColumnReference returns the column that a ref, each, fk, match or pick
+// column takes its values from. The bool result is false for columns of any
+// other type.
+func ColumnReference(c model.Column) (Reference, bool, error) {
+	if c.Generator.UnmarshalFunc == nil {
+		return Reference{}, false, nil
+	}
+
+	switch c.Type {
+	case "ref":
+		var g RefGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return Reference{}, false, fmt.Errorf("parsing ref process for %s: %w", c.Name, err)
+		}
+		return Reference{Table: g.Table, Column: g.Column}, true, nil
+
+	case "each":
+		var g EachGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return Reference{}, false, fmt.Errorf("parsing each process for %s: %w", c.Name, err)
+		}
+		return Reference{Table: g.Table, Column: g.Column}, true, nil
+
+	case "fk":
+		var g ForeignKeyGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return Reference{}, false, fmt.Errorf("parsing fk process for %s: %w", c.Name, err)
+		}
+		return Reference{Table: g.Table, Column: g.Column}, true, nil
+
+	case "match":
+		var g MatchGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return Reference{}, false, fmt.Errorf("parsing match process for %s: %w", c.Name, err)
+		}
+		return Reference{Table: g.SourceTable, Column: g.SourceValue}, true, nil
+
+	case "pick":
+		var g PickGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return Reference{}, false, fmt.Errorf("parsing pick process for %s: %w", c.Name, err)
+		}
+		return Reference{Table: g.SourceTable, Column: g.SourceValue}, true, nil
+
+	default:
+		return Reference{}, false, nil
+	}
+}
+
+// tableFuncs are the expression functions whose first argument is the name
+// of a table.
+var tableFuncs = []string{"match", "get_record", "get_column", "get_model"}
+
+// SortTables orders tables so that each one comes after the tables it reads
+// from. Tables that don't depend on each other keep their order, and tables
+// that read from dynamically named tables stay after the tables listed
+// before them.
+func SortTables(tables []model.Table) ([]model.Table, error) {
+	names := make([]string, len(tables))
+	dependencies := map[string][]string{}
+
+	// The first column of each table that depends on another, for errors.
+	reasons := map[[2]string]string{}
+
+	for i, t := range tables {
+		names[i] = t.Name
+
+		fieldDeps, err := tableFieldDependencies(t)
+		if err != nil {
+			return nil, t.Position.Wrap(fmt.Errorf("finding dependencies of %s: %w", t.Name, err))
+		}
+		for _, d := range fieldDeps {
+			dependencies[t.Name] = append(dependencies[t.Name], d.table)
+			if _, ok := reasons[[2]string{t.Name, d.table}]; !ok {
+				reasons[[2]string{t.Name, d.table}] = d.field
+			}
+		}
+
+		for _, c := range t.Columns {
+			deps, err := TableDependencies(c)
+			if err != nil {
+				return nil, c.Position.Wrap(fmt.Errorf("finding dependencies of %s.%s: %w", t.Name, c.Name, err))
+			}
+
+			for _, d := range deps {
+				dependencies[t.Name] = append(dependencies[t.Name], d)
+				if _, ok := reasons[[2]string{t.Name, d}]; !ok {
+					reasons[[2]string{t.Name, d}] = c.Name
+				}
+			}
+		}
+	}
+
+	// A table that reads from dynamically named tables could read from any
+	// table listed before it, so keep it after them, unless they read from it.
+	for i, t := range tables {
+		dynamic, err := ReadsDynamicTables(t)
+		if err != nil {
+			return nil, err
+		}
+		if !dynamic {
+			continue
+		}
+		for _, before := range names[:i] {
+			if !reaches(before, t.Name, dependencies) {
+				dependencies[t.Name] = append(dependencies[t.Name], before)
+			}
+		}
+	}
+
+	sorted, err := graph.Sort(names, dependencies)
+	if err != nil {
+		var cycle graph.CycleError
+		if !errors.As(err, &cycle) {
+			return nil, err
+		}
+
+		steps := make([]string, len(cycle.Path)-1)
+		for i := range steps {
+			from, to := cycle.Path[i], cycle.Path[i+1]
+			steps[i] = fmt.Sprintf("%s.%s references %s", from, reasons[[2]string{from, to}], to)
+		}
+		return nil, fmt.Errorf("tables can't be ordered because of a reference cycle (%s): %s", strings.Join(cycle.Path, " -> "), strings.Join(steps, ", "))
+	}
+
+	byName := lo.KeyBy(tables, func(t model.Table) string {
+		return t.Name
+	})
+
+	return lo.Map(sorted, func(name string, _ int) model.Table {
+		return byName[name]
+	}), nil
+}
+
+// reaches returns true if from depends on to, directly or through the
+// tables it depends on.
+func reaches(from, to string, dependencies map[string][]string) bool {
+	visited := map[string]bool{}
+	pending := []string{from}
+	for len(pending) > 0 {
+		n := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if n == to {
+			return true
+		}
+		if visited[n] {
+			continue
+		}
+		visited[n] = true
+		pending = append(pending, dependencies[n]...)
+	}
+	return false
+}
+
+// SortColumns orders a table's columns so that each one is generated after
+// the columns of the same table that it reads from. Columns that don't depend
+// on each other keep their order. The dependencies of fk, each and const
+// columns aren't considered, as they're generated before any other column.
+func SortColumns(t model.Table) ([]model.Column, error) {
+	names := make([]string, len(t.Columns))
+	dependencies := map[string][]string{}
+
+	for i, c := range t.Columns {
+		names[i] = c.Name
+		if lo.Contains(hoistedTypes, c.Type) {
+			continue
+		}
+
+		deps, err := ColumnDependencies(t, c)
+		if err != nil {
+			return nil, c.Position.Wrap(fmt.Errorf("finding dependencies of %s.%s: %w", t.Name, c.Name, err))
+		}
+		dependencies[c.Name] = deps
+	}
+
+	sorted, err := graph.Sort(names, dependencies)
+	if err != nil {
+		var cycle graph.CycleError
+		if !errors.As(err, &cycle) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("columns can't be ordered because of a reference cycle (%s)", strings.Join(cycle.Path, " -> "))
+	}
+
+	byName := lo.KeyBy(t.Columns, func(c model.Column) string {
+		return c.Name
+	})
+
+	return lo.Map(sorted, func(name string, _ int) model.Column {
+		return byName[name]
+	}), nil
+}
+
+// hoistedTypes are the column types that are generated before any other.
+var hoistedTypes = []string{"fk", "each", "const"}
+
+// ColumnDependencies returns the names of the columns in table t that column
+// c reads from. These are the match columns of match, pick and lookup columns,
+// the column a map column iterates over when it maps the same table, and any
+// column of the table used as a variable in expr, case, map and rel_date
+// expressions.
+func ColumnDependencies(t model.Table, c model.Column) ([]string, error) {
+	if c.Generator.UnmarshalFunc == nil {
+		return nil, nil
+	}
+
+	var columns []string
+
+	switch c.Type {
+	case "match":
+		var g MatchGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return nil, fmt.Errorf("parsing match process for %s: %w", c.Name, err)
+		}
+		columns = append(columns, g.MatchColumn)
+
+	case "pick":
+		var g PickGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return nil, fmt.Errorf("parsing pick process for %s: %w", c.Name, err)
+		}
+		columns = append(columns, g.MatchColumn)
+
+	case "lookup":
+		var g LookupGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return nil, fmt.Errorf("parsing lookup process for %s: %w", c.Name, err)
+		}
+		columns = append(columns, g.MatchColumn)
+
+	case "map":
+		var g MapGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return nil, fmt.Errorf("parsing map process for %s: %w", c.Name, err)
+		}
+		if g.Table == "" || g.Table == t.Name {
+			columns = append(columns, g.Column)
+		}
+	}
+
+	// Only these processors evaluate their expressions against the current
+	// row of the table.
+	if lo.Contains([]string{"expr", "case", "map", "rel_date", "relative_date"}, c.Type) {
+		expressions, err := columnExpressions(c)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range expressions {
+			names, err := inspectExpression(e)
+			if err != nil {
+				return nil, fmt.Errorf("parsing expression %q: %w", e, err)
+			}
+			columns = append(columns, names.identifiers...)
+		}
+	}
+
+	known := lo.Map(t.Columns, func(c model.Column, _ int) string {
+		return c.Name
+	})
+	columns = lo.Filter(lo.Uniq(columns), func(name string, _ int) bool {
+		return name != c.Name && lo.Contains(known, name)
+	})
+	if len(columns) == 0 {
+		return nil, nil
+	}
+	return columns, nil
+}
+
+// Requirement describes what other tables read from a table.
+type Requirement struct {
+	// All is true if other tables read whole records from the table (or
+	// columns that can't be determined).
+	All bool
+
+	// Columns are the columns that other tables read from the table.
+	Columns []string
+}
+
+// Requirements returns what other tables read from each table. ref and each
+// columns read the column they reference, as do fk columns without a filter
+// or repeat expression. match and pick columns read their source column and
+// value. Any other dependency on a table reads whole records, and every
+// table is read whole if any table reads from dynamically named tables.
+func Requirements(tables []model.Table) (map[string]Requirement, error) {
+	requirements := map[string]Requirement{}
+
+	for _, t := range tables {
+		// Tables named by anything other than a string literal could be any
+		// table, so every table has to be kept whole.
+		dynamic, err := ReadsDynamicTables(t)
+		if err != nil {
+			return nil, err
+		}
+		if dynamic {
+			for _, other := range tables {
+				requirements[other.Name] = Requirement{All: true}
+			}
+			return requirements, nil
+		}
+
+		// Count and where expressions can read whole records.
+		fieldDeps, err := tableFieldDependencies(t)
+		if err != nil {
+			return nil, t.Position.Wrap(fmt.Errorf("finding dependencies of %s: %w", t.Name, err))
+		}
+		for _, d := range fieldDeps {
+			r := requirements[d.table]
+			r.All = true
+			requirements[d.table] = r
+		}
+
+		for _, c := range t.Columns {
+			deps, err := TableDependencies(c)
+			if err != nil {
+				return nil, c.Position.Wrap(fmt.Errorf("finding dependencies of %s.%s: %w", t.Name, c.Name, err))
+			}
+
+			columns, err := referencedColumns(c)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, d := range deps {
+				if d == t.Name {
+					continue
+				}
+
+				r := requirements[d]
+				if cols, ok := columns[d]; ok {
+					r.Columns = lo.Uniq(append(r.Columns, cols...))
+				} else {
+					r.All = true
+				}
+				requirements[d] = r
+			}
+		}
+	}
+
+	return requirements, nil
+}
+
+// referencedColumns returns the columns of other tables that a ref, each,
+// fk, match or pick column reads, for columns that only read specific
+// columns.
+func referencedColumns(c model.Column) (map[string][]string, error) {
+	if c.Generator.UnmarshalFunc == nil {
+		return nil, nil
+	}
+
+	switch c.Type {
+	case "ref", "each":
+		ref, _, err := ColumnReference(c)
+		if err != nil {
+			return nil, err
+		}
+		return map[string][]string{ref.Table: {ref.Column}}, nil
+
+	case "fk":
+		var g ForeignKeyGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return nil, fmt.Errorf("parsing fk process for %s: %w", c.Name, err)
+		}
+		if g.Filter != "" || g.Repeat != "" {
+			return nil, nil
+		}
+		return map[string][]string{g.Table: {g.Column}}, nil
+
+	case "match":
+		var g MatchGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return nil, fmt.Errorf("parsing match process for %s: %w", c.Name, err)
+		}
+		return map[string][]string{g.SourceTable: {g.SourceColumn, g.SourceValue}}, nil
+
+	case "pick":
+		var g PickGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return nil, fmt.Errorf("parsing pick process for %s: %w", c.Name, err)
+		}
+		return map[string][]string{g.SourceTable: {g.SourceColumn, g.SourceValue}}, nil
+	}
+
+	return nil, nil
+}
+
+// ReadsFrom returns the names of the other tables that a table's count,
+// where expression and columns read from, in the order they're first read.
+// Tables named dynamically aren't included (see ReadsDynamicTables).
+func ReadsFrom(t model.Table) ([]string, error) {
+	fieldDeps, err := tableFieldDependencies(t)
+	if err != nil {
+		return nil, t.Position.Wrap(fmt.Errorf("finding dependencies of %s: %w", t.Name, err))
+	}
+	tables := lo.Map(fieldDeps, func(d fieldDependency, _ int) string {
+		return d.table
+	})
+
+	for _, c := range t.Columns {
+		deps, err := TableDependencies(c)
+		if err != nil {
+			return nil, c.Position.Wrap(fmt.Errorf("finding dependencies of %s.%s: %w", t.Name, c.Name, err))
+		}
+		tables = append(tables, deps...)
+	}
+
+	return lo.Without(lo.Uniq(tables), t.Name), nil
+}
+
+// ReadsDynamicTables returns true if any of a table's count, where or column
+// expressions call a table function (e.g. get_record) with anything other
+// than a string literal, in which case the tables it reads from can't be
+// determined.
+func ReadsDynamicTables(t model.Table) (bool, error) {
+	for _, f := range [][2]string{{"count", t.CountExpression}, {"where", t.Where}} {
+		if f[1] == "" {
+			continue
+		}
+
+		names, err := inspectExpression(f[1])
+		if err != nil {
+			return false, t.Position.Wrap(fmt.Errorf("parsing %s expression %q of %s: %w", f[0], f[1], t.Name, err))
+		}
+		if names.dynamicTables {
+			return true, nil
+		}
+	}
+
+	for _, c := range t.Columns {
+		if c.Generator.UnmarshalFunc == nil {
+			continue
+		}
+
+		expressions, err := columnExpressions(c)
+		if err != nil {
+			return false, c.Position.Wrap(fmt.Errorf("finding dependencies of %s.%s: %w", t.Name, c.Name, err))
+		}
+		for _, e := range expressions {
+			names, err := inspectExpression(e)
+			if err != nil {
+				return false, c.Position.Wrap(fmt.Errorf("parsing expression %q of %s.%s: %w", e, t.Name, c.Name, err))
+			}
+			if names.dynamicTables {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// fieldDependency is a table that one of a table's own fields (rather than
+// one of its columns) reads from.
+type fieldDependency struct {
+	field string
+	table string
+}
+
+// tableFieldDependencies returns the other tables that a table's count and
+// where expressions read from.
+func tableFieldDependencies(t model.Table) ([]fieldDependency, error) {
+	var deps []fieldDependency
+	for _, f := range [][2]string{{"count", t.CountExpression}, {"where", t.Where}} {
+		if f[1] == "" {
+			continue
+		}
+
+		names, err := inspectExpression(f[1])
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s expression %q: %w", f[0], f[1], err)
+		}
+		for _, table := range names.tables {
+			if table != t.Name {
+				deps = append(deps, fieldDependency{field: f[0], table: table})
+			}
+		}
+	}
+	return deps, nil
+}
+
+// TableDependencies returns the names of the tables that a column's
+// processor reads from, including tables named in calls to match,
+// get_record, get_column and get_model in its expressions.
+func TableDependencies(c model.Column) ([]string, error) {
+	if c.Generator.UnmarshalFunc == nil {
+		return nil, nil
+	}
+
+	var tables []string
+
+	ref, ok, err := ColumnReference(c)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		tables = append(tables, ref.Table)
+	}
+
+	switch c.Type {
+	case "lookup":
+		var g LookupGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return nil, fmt.Errorf("parsing lookup process for %s: %w", c.Name, err)
+		}
+		for _, lt := range g.LookupTables {
+			tables = append(tables, lt.SourceTable)
+		}
+
+	case "map":
+		var g MapGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return nil, fmt.Errorf("parsing map process for %s: %w", c.Name, err)
+		}
+		tables = append(tables, g.Table)
+
+	case "range":
+		var g RangeGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return nil, fmt.Errorf("parsing range process for %s: %w", c.Name, err)
+		}
+		tables = append(tables, g.Table)
+	}
+
+	expressions, err := columnExpressions(c)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range expressions {
+		names, err := inspectExpression(e)
+		if err != nil {
+			return nil, fmt.Errorf("parsing expression %q: %w", e, err)
+		}
+		tables = append(tables, names.tables...)
+	}
+
+	if tables = lo.Uniq(lo.Compact(tables)); len(tables) == 0 {
+		return nil, nil
+	}
+	return tables, nil
+}
+
+// columnExpressions returns the expressions that a column's processor
+// evaluates.
+func columnExpressions(c model.Column) ([]string, error) {
+	var expressions []string
+
+	switch c.Type {
+	case "expr":
+		var g ExprGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return nil, fmt.Errorf("parsing expr process for %s: %w", c.Name, err)
+		}
+		expressions = append(expressions, g.Expression)
+
+	case "case":
+		var g CaseGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return nil, fmt.Errorf("parsing case process for %s: %w", c.Name, err)
+		}
+		for _, cond := range g {
+			expressions = append(expressions, cond.When, cond.Then)
+		}
+
+	case "dist":
+		var g DistGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return nil, fmt.Errorf("parsing dist process for %s: %w", c.Name, err)
+		}
+		expressions = append(expressions, g.Expression)
+
+	case "map":
+		var g MapGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return nil, fmt.Errorf("parsing map process for %s: %w", c.Name, err)
+		}
+		expressions = append(expressions, g.Expression)
+
+	case "lookup":
+		var g LookupGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return nil, fmt.Errorf("parsing lookup process for %s: %w", c.Name, err)
+		}
+		expressions = append(expressions, g.Repeat)
+		for _, lt := range g.LookupTables {
+			expressions = append(expressions, lt.Expression, lt.Predicate)
+		}
+
+	case "fk":
+		var g ForeignKeyGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return nil, fmt.Errorf("parsing fk process for %s: %w", c.Name, err)
+		}
+		expressions = append(expressions, g.Filter, g.Repeat)
+
+	case "rel_date", "relative_date":
+		var g RelDateGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return nil, fmt.Errorf("parsing rel_date process for %s: %w", c.Name, err)
+		}
+		if _, ok := model.ParseDate(g.Date, g.Format); !ok && g.Date != "now" {
+			expressions = append(expressions, g.Date)
+		}
+		for _, v := range []any{g.After, g.Before} {
+			if s, ok := v.(string); ok {
+				expressions = append(expressions, s)
+			}
+		}
+	}
+
+	return lo.Compact(expressions), nil
+}
+
+// expressionNames holds the names that an expression refers to.
+type expressionNames struct {
+	// tables are named by string literals in calls to table functions.
+	tables []string
+
+	// identifiers are the variables and functions the expression uses.
+	identifiers []string
+
+	// dynamicTables is true if a table function is called with anything
+	// other than a string literal.
+	dynamicTables bool
+}
+
+// inspectExpression returns the names an expression refers to. Tables named
+// by anything other than a string literal can't be detected.
+func inspectExpression(expression string) (expressionNames, error) {
+	tree, err := parser.Parse(expression)
+	if err != nil {
+		return expressionNames{}, err
+	}
+
+	v := &expressionVisitor{}
+	ast.Walk(&tree.Node, v)
+	return v.names, nil
+}
+
+type expressionVisitor struct {
+	names expressionNames
+}
+
+func (v *expressionVisitor) Visit(node *ast.Node) {
+	switch n := (*node).(type) {
+	case *ast.IdentifierNode:
+		v.names.identifiers = append(v.names.identifiers, n.Value)
+
+	case *ast.CallNode:
+		callee, ok := n.Callee.(*ast.IdentifierNode)
+		if !ok || len(n.Arguments) == 0 || !lo.Contains(tableFuncs, callee.Value) {
+			return
+		}
+		if table, ok := n.Arguments[0].(*ast.StringNode); ok {
+			v.names.tables = append(v.names.tables, table.Value)
+		} else {
+			v.names.dynamicTables = true
+		}
+	}
+}
@@ -0,0 +1,509 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColumnReference(t *testing.T) {
+	cases := []struct {
+		name   string
+		column model.Column
+		exp    Reference
+		expOK  bool
+	}{
+		{
+			name:   "ref",
+			column: model.Column{Type: "ref", Generator: model.ToRawMessage(t, map[string]any{"table": "person", "column": "id"})},
+			exp:    Reference{Table: "person", Column: "id"},
+			expOK:  true,
+		},
+		{
+			name:   "each",
+			column: model.Column{Type: "each", Generator: model.ToRawMessage(t, map[string]any{"table": "event", "column": "id"})},
+			exp:    Reference{Table: "event", Column: "id"},
+			expOK:  true,
+		},
+		{
+			name:   "fk",
+			column: model.Column{Type: "fk", Generator: model.ToRawMessage(t, map[string]any{"table": "account", "column": "id", "repeat": "1"})},
+			exp:    Reference{Table: "account", Column: "id"},
+			expOK:  true,
+		},
+		{
+			name:   "match",
+			column: model.Column{Type: "match", Generator: model.ToRawMessage(t, map[string]any{"source_table": "market", "source_column": "code", "source_value": "id", "match_column": "market"})},
+			exp:    Reference{Table: "market", Column: "id"},
+			expOK:  true,
+		},
+		{
+			name:   "pick",
+			column: model.Column{Type: "pick", Generator: model.ToRawMessage(t, map[string]any{"source_table": "market", "source_column": "code", "source_value": "name", "match_column": "market"})},
+			exp:    Reference{Table: "market", Column: "name"},
+			expOK:  true,
+		},
+		{
+			name:   "other type",
+			column: model.Column{Type: "gen", Generator: model.ToRawMessage(t, map[string]any{"value": "${uuid}"})},
+		},
+		{
+			name:   "no processor",
+			column: model.Column{Type: "ref"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			act, ok, err := ColumnReference(c.column)
+			assert.NoError(t, err)
+			assert.Equal(t, c.expOK, ok)
+			assert.Equal(t, c.exp, act)
+		})
+	}
+}
+
+func TestTableDependencies(t *testing.T) {
+	cases := []struct {
+		name   string
+		column model.Column
+		exp    []string
+	}{
+		{
+			name:   "ref",
+			column: model.Column{Type: "ref", Generator: model.ToRawMessage(t, map[string]any{"table": "person", "column": "id"})},
+			exp:    []string{"person"},
+		},
+		{
+			name: "lookup",
+			column: model.Column{Type: "lookup", Generator: model.ToRawMessage(t, map[string]any{
+				"tables": []map[string]any{
+					{"source_table": "a", "expression": "1"},
+					{"source_table": "b", "expression": "get_column('c', 'id')"},
+				},
+			})},
+			exp: []string{"a", "b", "c"},
+		},
+		{
+			name:   "map",
+			column: model.Column{Type: "map", Generator: model.ToRawMessage(t, map[string]any{"table": "person", "expression": "1"})},
+			exp:    []string{"person"},
+		},
+		{
+			name:   "range",
+			column: model.Column{Type: "range", Generator: model.ToRawMessage(t, map[string]any{"type": "int", "table": "person"})},
+			exp:    []string{"person"},
+		},
+		{
+			name:   "expr",
+			column: model.Column{Type: "expr", Generator: model.ToRawMessage(t, map[string]any{"expression": "get_record('a', 0).id + len(get_model(\"b\")) + match('a', 'id', 1, 'name')"})},
+			exp:    []string{"a", "b"},
+		},
+		{
+			name:   "expr with dynamic table",
+			column: model.Column{Type: "expr", Generator: model.ToRawMessage(t, map[string]any{"expression": "get_column(table, 'id')"})},
+		},
+		{
+			name: "case",
+			column: model.Column{Type: "case", Generator: model.ToRawMessage(t, []map[string]any{
+				{"when": "len(get_column('a', 'id')) > 1", "then": "'x'"},
+				{"when": "true", "then": "get_record('b', 0).id"},
+			})},
+			exp: []string{"a", "b"},
+		},
+		{
+			name:   "rel_date",
+			column: model.Column{Type: "rel_date", Generator: model.ToRawMessage(t, map[string]any{"date": "get_record('a', 0).created", "unit": "day", "after": -1, "before": 1})},
+			exp:    []string{"a"},
+		},
+		{
+			name:   "fk",
+			column: model.Column{Type: "fk", Generator: model.ToRawMessage(t, map[string]any{"table": "a", "column": "id", "filter": "len(get_column('b', 'id')) > 0"})},
+			exp:    []string{"a", "b"},
+		},
+		{
+			name:   "no dependencies",
+			column: model.Column{Type: "gen", Generator: model.ToRawMessage(t, map[string]any{"value": "${uuid}"})},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			act, err := TableDependencies(c.column)
+			assert.NoError(t, err)
+			assert.Equal(t, c.exp, act)
+		})
+	}
+}
+
+func TestTableDependenciesInvalidExpression(t *testing.T) {
+	column := model.Column{Type: "expr", Generator: model.ToRawMessage(t, map[string]any{"expression": "get_record('a', "})}
+
+	_, err := TableDependencies(column)
+	assert.ErrorContains(t, err, `parsing expression "get_record('a', "`)
+}
+
+func TestSortTables(t *testing.T) {
+	ref := func(table string) model.Column {
+		return model.Column{Name: table + "_id", Type: "ref", Generator: model.ToRawMessage(t, map[string]any{"table": table, "column": "id"})}
+	}
+	expr := func(expression string) model.Column {
+		return model.Column{Name: "value", Type: "expr", Generator: model.ToRawMessage(t, map[string]any{"expression": expression})}
+	}
+
+	cases := []struct {
+		name   string
+		tables []model.Table
+		exp    []string
+		expErr string
+	}{
+		{
+			name: "already ordered",
+			tables: []model.Table{
+				{Name: "a"},
+				{Name: "b", Columns: []model.Column{ref("a")}},
+			},
+			exp: []string{"a", "b"},
+		},
+		{
+			name: "reordered",
+			tables: []model.Table{
+				{Name: "c", Columns: []model.Column{ref("b")}},
+				{Name: "b", Columns: []model.Column{expr("get_record('a', 0).id")}},
+				{Name: "a"},
+				{Name: "d"},
+			},
+			exp: []string{"a", "b", "c", "d"},
+		},
+		{
+			name: "input tables",
+			tables: []model.Table{
+				{Name: "a", Columns: []model.Column{ref("input")}},
+			},
+			exp: []string{"a"},
+		},
+		{
+			name: "cycle",
+			tables: []model.Table{
+				{Name: "a", Columns: []model.Column{ref("b")}},
+				{Name: "b", Columns: []model.Column{expr("get_column('a', 'id')")}},
+			},
+			expErr: "tables can't be ordered because of a reference cycle (a -> b -> a): a.b_id references b, b.value references a",
+		},
+		{
+			name: "count expressions",
+			tables: []model.Table{
+				{Name: "b", CountExpression: "len(get_column('a', 'id')) * 3"},
+				{Name: "a"},
+			},
+			exp: []string{"a", "b"},
+		},
+		{
+			name: "count expression cycle",
+			tables: []model.Table{
+				{Name: "a", Columns: []model.Column{ref("b")}},
+				{Name: "b", CountExpression: "len(get_column('a', 'id'))"},
+			},
+			expErr: "tables can't be ordered because of a reference cycle (a -> b -> a): a.b_id references b, b.count references a",
+		},
+		{
+			name: "where expressions",
+			tables: []model.Table{
+				{Name: "b", Where: "id in get_column('a', 'id')"},
+				{Name: "a", Where: "get_record('a', 0).id != id"},
+			},
+			exp: []string{"a", "b"},
+		},
+		{
+			name: "dynamic tables",
+			tables: []model.Table{
+				{Name: "a", Columns: []model.Column{ref("c")}},
+				{Name: "b", Columns: []model.Column{expr("get_record('a' + '', 0).id")}},
+				{Name: "c"},
+			},
+			exp: []string{"c", "a", "b"},
+		},
+		{
+			name: "dynamic tables read from",
+			tables: []model.Table{
+				{Name: "a", Columns: []model.Column{ref("b")}},
+				{Name: "b", Columns: []model.Column{expr("get_record('c' + '', 0).id")}},
+				{Name: "c"},
+			},
+			exp: []string{"b", "a", "c"},
+		},
+		{
+			name: "dynamic tables in count and where expressions",
+			tables: []model.Table{
+				{Name: "a", Columns: []model.Column{ref("c")}},
+				{Name: "b", CountExpression: "len(get_column('a' + '', 'id'))"},
+				{Name: "d", Where: "get_record('a' + '', 0).id != id"},
+				{Name: "c"},
+			},
+			exp: []string{"c", "a", "b", "d"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			act, err := SortTables(c.tables)
+			if c.expErr != "" {
+				assert.EqualError(t, err, c.expErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.exp, lo.Map(act, func(t model.Table, _ int) string {
+				return t.Name
+			}))
+		})
+	}
+}
+
+func TestColumnDependencies(t *testing.T) {
+	table := model.Table{
+		Name: "person",
+		Columns: []model.Column{
+			{Name: "id"}, {Name: "name"}, {Name: "country"}, {Name: "born"},
+		},
+	}
+
+	cases := []struct {
+		name   string
+		column model.Column
+		exp    []string
+	}{
+		{
+			name:   "expr",
+			column: model.Column{Name: "greeting", Type: "expr", Generator: model.ToRawMessage(t, map[string]any{"expression": "'hello ' + name + string(rand(10)) + other"})},
+			exp:    []string{"name"},
+		},
+		{
+			name: "case",
+			column: model.Column{Name: "region", Type: "case", Generator: model.ToRawMessage(t, []map[string]any{
+				{"when": "country == 'uk'", "then": "'emea'"},
+				{"when": "true", "then": "name"},
+			})},
+			exp: []string{"country", "name"},
+		},
+		{
+			name:   "rel_date",
+			column: model.Column{Name: "joined", Type: "rel_date", Generator: model.ToRawMessage(t, map[string]any{"date": "born", "unit": "year", "after": 18, "before": 30})},
+			exp:    []string{"born"},
+		},
+		{
+			name:   "map same table",
+			column: model.Column{Name: "n", Type: "map", Generator: model.ToRawMessage(t, map[string]any{"column": "country", "expression": "id + value_index"})},
+			exp:    []string{"country", "id"},
+		},
+		{
+			name:   "map other table",
+			column: model.Column{Name: "n", Type: "map", Generator: model.ToRawMessage(t, map[string]any{"table": "pet", "column": "country", "expression": "value_index"})},
+		},
+		{
+			name:   "match",
+			column: model.Column{Name: "market", Type: "match", Generator: model.ToRawMessage(t, map[string]any{"source_table": "market", "source_column": "code", "source_value": "id", "match_column": "country"})},
+			exp:    []string{"country"},
+		},
+		{
+			name:   "lookup",
+			column: model.Column{Name: "market", Type: "lookup", Generator: model.ToRawMessage(t, map[string]any{"match_column": "country", "tables": []map[string]any{{"source_table": "market", "expression": "name"}}})},
+			exp:    []string{"country"},
+		},
+		{
+			name:   "self reference",
+			column: model.Column{Name: "name", Type: "expr", Generator: model.ToRawMessage(t, map[string]any{"expression": "name"})},
+		},
+		{
+			name:   "dist",
+			column: model.Column{Name: "n", Type: "dist", Generator: model.ToRawMessage(t, map[string]any{"expression": "id"})},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			act, err := ColumnDependencies(table, c.column)
+			assert.NoError(t, err)
+			assert.Equal(t, c.exp, act)
+		})
+	}
+}
+
+func TestSortColumns(t *testing.T) {
+	expr := func(name, expression string) model.Column {
+		return model.Column{Name: name, Type: "expr", Generator: model.ToRawMessage(t, map[string]any{"expression": expression})}
+	}
+	inc := model.Column{Name: "id", Type: "inc", Generator: model.ToRawMessage(t, map[string]any{"start": 1})}
+
+	cases := []struct {
+		name    string
+		columns []model.Column
+		exp     []string
+		expErr  string
+	}{
+		{
+			name:    "already ordered",
+			columns: []model.Column{inc, expr("a", "id")},
+			exp:     []string{"id", "a"},
+		},
+		{
+			name:    "reordered",
+			columns: []model.Column{expr("b", "a + 1"), expr("a", "id"), inc},
+			exp:     []string{"id", "a", "b"},
+		},
+		{
+			name: "hoisted dependencies are ignored",
+			columns: []model.Column{
+				{Name: "f", Type: "fk", Generator: model.ToRawMessage(t, map[string]any{"table": "t", "column": "id", "filter": "a > 1"})},
+				expr("a", "f"),
+			},
+			exp: []string{"f", "a"},
+		},
+		{
+			name:    "cycle",
+			columns: []model.Column{expr("a", "b"), expr("b", "a")},
+			expErr:  "columns can't be ordered because of a reference cycle (a -> b -> a)",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			act, err := SortColumns(model.Table{Name: "t", Columns: c.columns})
+			if c.expErr != "" {
+				assert.EqualError(t, err, c.expErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.exp, lo.Map(act, func(c model.Column, _ int) string {
+				return c.Name
+			}))
+		})
+	}
+}
+
+func TestRequirements(t *testing.T) {
+	column := func(name, typ string, processor map[string]any) model.Column {
+		return model.Column{Name: name, Type: typ, Generator: model.ToRawMessage(t, processor)}
+	}
+
+	cases := []struct {
+		name   string
+		tables []model.Table
+		exp    map[string]Requirement
+	}{
+		{
+			name: "no dependencies",
+			tables: []model.Table{
+				{Name: "a", Columns: []model.Column{column("id", "inc", map[string]any{"start": 1})}},
+			},
+			exp: map[string]Requirement{},
+		},
+		{
+			name: "referenced columns",
+			tables: []model.Table{
+				{Name: "a"},
+				{Name: "b", Columns: []model.Column{
+					column("a_id", "ref", map[string]any{"table": "a", "column": "id"}),
+					column("a_code", "each", map[string]any{"table": "a", "column": "code"}),
+					column("a_name", "pick", map[string]any{"source_table": "a", "source_column": "code", "source_value": "name", "match_column": "a_code"}),
+				}},
+			},
+			exp: map[string]Requirement{
+				"a": {Columns: []string{"id", "code", "name"}},
+			},
+		},
+		{
+			name: "fk",
+			tables: []model.Table{
+				{Name: "a"},
+				{Name: "b", Columns: []model.Column{
+					column("a_id", "fk", map[string]any{"table": "a", "column": "id"}),
+				}},
+				{Name: "c", Columns: []model.Column{
+					column("b_id", "fk", map[string]any{"table": "b", "column": "id", "filter": "b.a_id > 1"}),
+				}},
+			},
+			exp: map[string]Requirement{
+				"a": {Columns: []string{"id"}},
+				"b": {All: true},
+			},
+		},
+		{
+			name: "whole records",
+			tables: []model.Table{
+				{Name: "a"},
+				{Name: "b", Columns: []model.Column{
+					column("a_id", "ref", map[string]any{"table": "a", "column": "id"}),
+					column("value", "expr", map[string]any{"expression": "get_record('a', 0).name"}),
+				}},
+			},
+			exp: map[string]Requirement{
+				"a": {All: true, Columns: []string{"id"}},
+			},
+		},
+		{
+			name: "own table",
+			tables: []model.Table{
+				{Name: "a", Columns: []model.Column{
+					column("parent_id", "ref", map[string]any{"table": "a", "column": "id"}),
+				}},
+			},
+			exp: map[string]Requirement{},
+		},
+		{
+			name: "dynamic tables",
+			tables: []model.Table{
+				{Name: "a"},
+				{Name: "b", Columns: []model.Column{
+					column("a_id", "ref", map[string]any{"table": "a", "column": "id"}),
+				}},
+				{Name: "c", Columns: []model.Column{
+					column("value", "expr", map[string]any{"expression": "get_record('a' + '', 0).name"}),
+				}},
+			},
+			exp: map[string]Requirement{
+				"a": {All: true},
+				"b": {All: true},
+				"c": {All: true},
+			},
+		},
+		{
+			name: "dynamic tables in where expressions",
+			tables: []model.Table{
+				{Name: "a"},
+				{Name: "b", Where: "get_record('a' + '', 0).id != id"},
+			},
+			exp: map[string]Requirement{
+				"a": {All: true},
+				"b": {All: true},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			act, err := Requirements(c.tables)
+			assert.NoError(t, err)
+			assert.Equal(t, c.exp, act)
+		})
+	}
+}
+
+func TestReadsFrom(t *testing.T) {
+	table := model.Table{
+		Name: "pet",
+		Columns: []model.Column{
+			{Name: "person_id", Type: "ref", Generator: model.ToRawMessage(t, map[string]any{"table": "person", "column": "id"})},
+			{Name: "parent_id", Type: "ref", Generator: model.ToRawMessage(t, map[string]any{"table": "pet", "column": "id"})},
+			{Name: "owner", Type: "expr", Generator: model.ToRawMessage(t, map[string]any{"expression": "get_record('person', 0).name + get_record('market', 0).code"})},
+			{Name: "name", Type: "gen", Generator: model.ToRawMessage(t, map[string]any{"value": "${pet_name}"})},
+		},
+	}
+
+	act, err := ReadsFrom(table)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"person", "market"}, act)
+}
@@ -2,7 +2,6 @@ package generator
 
 import (
 	"fmt"
-	"math/rand"
 	"reflect"
 
 	"github.com/codingconcepts/dg/internal/pkg/model"
@@ -17,8 +16,10 @@ type DistGenerator struct {
 
 func (g *DistGenerator) Generate(t model.Table, c model.Column, files map[string]model.CSVFile) error {
 
+	r := columnRand(t, c)
+
 	if g.Expression != "" {
-		ec := &ExprContext{Files: files}
+		ec := &ExprContext{Files: files, Rand: r}
 		env := ec.makeEnv()
 		result, err := ec.evaluate(g.Expression, env)
 		if err != nil {
@@ -40,11 +41,12 @@ func (g *DistGenerator) Generate(t model.Table, c model.Column, files map[string
 			}
 		}
 
-		// Count occurrences and use them as weights
+		// Count occurrences and use them as weights, keeping the order in
+		// which values first appear so that seeded runs are reproducible.
 		counts := lo.CountValues(values)
-		for value, count := range counts {
+		for _, value := range lo.Uniq(values) {
 			g.Values = append(g.Values, value)
-			g.Weights = append(g.Weights, count)
+			g.Weights = append(g.Weights, counts[value])
 		}
 	}
 
@@ -95,7 +97,7 @@ func (g *DistGenerator) Generate(t model.Table, c model.Column, files map[string
 		}
 	}
 
-	rand.Shuffle(len(lines), func(i, j int) {
+	r.Shuffle(len(lines), func(i, j int) {
 		lines[i], lines[j] = lines[j], lines[i]
 	})
 
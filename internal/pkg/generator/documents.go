@@ -0,0 +1,62 @@
+package generator
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/brianvoe/gofakeit/v7"
+)
+
+// generateCPF returns a valid Brazilian CPF number drawn from the given
+// faker, so that it honours the faker's seed.
+func generateCPF(f *gofakeit.Faker) string {
+	digits := make([]int, 9, 11)
+	for i := range digits {
+		digits[i] = f.IntN(10)
+	}
+	digits = append(digits, documentCheckDigit(digits, 11))
+	digits = append(digits, documentCheckDigit(digits, 11))
+
+	return joinDigits(digits)
+}
+
+// generateCNPJ returns a valid Brazilian CNPJ number (headquarters branch
+// 0001) drawn from the given faker, so that it honours the faker's seed.
+func generateCNPJ(f *gofakeit.Faker) string {
+	digits := make([]int, 12, 14)
+	for i := 0; i < 8; i++ {
+		digits[i] = f.IntN(10)
+	}
+	digits[11] = 1
+	digits = append(digits, documentCheckDigit(digits, 9))
+	digits = append(digits, documentCheckDigit(digits, 9))
+
+	return joinDigits(digits)
+}
+
+// documentCheckDigit computes a modulo 11 check digit, applying weights from
+// 2 up to maxWeight (wrapping back to 2) from the rightmost digit.
+func documentCheckDigit(digits []int, maxWeight int) int {
+	sum := 0
+	weight := 2
+	for i := len(digits) - 1; i >= 0; i-- {
+		sum += digits[i] * weight
+		if weight++; weight > maxWeight {
+			weight = 2
+		}
+	}
+
+	mod := (sum * 10) % 11
+	if mod == 10 {
+		return 0
+	}
+	return mod
+}
+
+func joinDigits(digits []int) string {
+	var b strings.Builder
+	for _, d := range digits {
+		b.WriteString(strconv.Itoa(d))
+	}
+	return b.String()
+}
@@ -0,0 +1,31 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/codingconcepts/dg/internal/pkg/random"
+	"github.com/martinusso/go-docs/cnpj"
+	"github.com/martinusso/go-docs/cpf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateDocuments(t *testing.T) {
+	f := gofakeit.NewFaker(random.New(), false)
+
+	for i := 0; i < 100; i++ {
+		assert.True(t, cpf.Valid(generateCPF(f)))
+		assert.True(t, cnpj.Valid(generateCNPJ(f)))
+	}
+}
+
+func TestGenerateDocumentsSeeded(t *testing.T) {
+	random.Seed(42)
+	f1 := gofakeit.NewFaker(random.New(), false)
+
+	random.Seed(42)
+	f2 := gofakeit.NewFaker(random.New(), false)
+
+	assert.Equal(t, generateCPF(f1), generateCPF(f2))
+	assert.Equal(t, generateCNPJ(f1), generateCNPJ(f2))
+}
@@ -2,6 +2,7 @@ package generator
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/codingconcepts/dg/internal/pkg/model"
 
@@ -12,10 +13,23 @@ import (
 type EachGenerator struct {
 	Table  string `yaml:"table"`
 	Column string `yaml:"column"`
+	Sample string `yaml:"sample"`
 }
 
+const (
+	// SampleSequential takes combinations in order, starting again from the
+	// first combination if the table's count is higher than the number of
+	// combinations.
+	SampleSequential = "sequential"
+
+	// SampleRandom takes a random selection of combinations, in a random
+	// order, without repeating any.
+	SampleRandom = "random"
+)
+
 // Generate looks for any each type columns for a table, and
 // returns their Cartesian product back into the given files map.
+// Combinations are picked by index, so the product is never built.
 func (g EachGenerator) Generate(t model.Table, files map[string]model.CSVFile) error {
 	cols := lo.Filter(t.Columns, func(c model.Column, _ int) bool {
 		return c.Type == "each"
@@ -25,11 +39,12 @@ func (g EachGenerator) Generate(t model.Table, files map[string]model.CSVFile) e
 		return nil
 	}
 
-	var preCartesian [][]string
+	var arrays [][]string
+	sample := ""
 	for _, col := range cols {
 		var gCol EachGenerator
 		if err := col.Generator.UnmarshalFunc(&gCol); err != nil {
-			return fmt.Errorf("parsing each process for %s.%s: %w", t.Name, col.Name, err)
+			return col.Position.Wrap(fmt.Errorf("parsing each process for %s.%s: %w", t.Name, col.Name, err))
 		}
 
 		srcTable := files[gCol.Table]
@@ -37,35 +52,76 @@ func (g EachGenerator) Generate(t model.Table, files map[string]model.CSVFile) e
 		srcColumnIndex := lo.IndexOf(srcTable.Header, srcColumn)
 
 		if srcTable.Name == "" {
-			return fmt.Errorf("table %q not found", gCol.Table)
+			return col.Position.Wrap(fmt.Errorf("table %q not found", gCol.Table))
 		}
 		if srcColumnIndex == -1 {
-			return fmt.Errorf("column %s not found in table %q", srcColumn, srcTable.Name)
+			return col.Position.Wrap(fmt.Errorf("column %s not found in table %q", srcColumn, srcTable.Name))
 		}
 		if len(srcTable.Lines)-1 < srcColumnIndex {
-			return fmt.Errorf("column %q out of bounds for table %q", srcColumn, srcTable.Name)
+			return col.Position.Wrap(fmt.Errorf("column %q out of bounds for table %q", srcColumn, srcTable.Name))
+		}
+
+		if gCol.Sample != "" {
+			if sample != "" && gCol.Sample != sample {
+				return col.Position.Wrap(fmt.Errorf("each columns of %q have different samples: %q and %q", t.Name, sample, gCol.Sample))
+			}
+			sample = gCol.Sample
 		}
 
-		preCartesian = append(preCartesian, srcTable.Lines[srcColumnIndex])
+		arrays = append(arrays, srcTable.Lines[srcColumnIndex])
 	}
 
-	// Compute Cartesian product of all columns.
-	cartesianColumns := Transpose(CartesianProduct(preCartesian...))
+	p := newProduct(arrays...)
+
+	count := t.Count
+	if count == 0 {
+		if p.size == -1 {
+			return fmt.Errorf("too many combinations of each columns for %q, so a count is required", t.Name)
+		}
+		count = p.size
 
-	// if count is set adjust the cartesian product to fit the count
-	if t.Count > 0 {
-		newCartesianColumns := make([][]string, len(cartesianColumns))
-		size := len(cartesianColumns[0])
-		for c := range cartesianColumns {
-			for i := 0; i < t.Count; i++ {
-				newCartesianColumns[c] = append(newCartesianColumns[c], cartesianColumns[c][i%size])
+		// Scaling never adds combinations, as there aren't any more.
+		if !t.Fixed {
+			count = min(model.ScaleCount(count, scale), count)
+		}
+	}
+	if p.size == 0 && count > 0 {
+		return fmt.Errorf("no combinations of each columns for %q", t.Name)
+	}
+
+	var index func(i int) int
+	switch sample {
+	case "", SampleSequential:
+		index = func(i int) int {
+			if p.size == -1 {
+				return i
 			}
+			return i % p.size
+		}
+
+	case SampleRandom:
+		if p.size != -1 && count > p.size {
+			return fmt.Errorf("can't sample %d combinations of each columns for %q, as there are only %d", count, t.Name, p.size)
+		}
+		size := p.size
+		if size == -1 {
+			size = math.MaxInt
 		}
-		cartesianColumns = newCartesianColumns
+		indexes := sampleIndexes(columnRand(t, cols[0]), size, count)
+		index = func(i int) int {
+			return indexes[i]
+		}
+
+	default:
+		return fmt.Errorf("invalid sample %q for each columns of %q", sample, t.Name)
 	}
-	// Add the header
-	for i, col := range cartesianColumns {
-		AddTable(t, cols[i].Name, col, files)
+
+	for c, col := range cols {
+		lines := make([]string, count)
+		for i := range lines {
+			lines[i] = p.value(index(i), c)
+		}
+		AddTable(t, col.Name, lines, files)
 	}
 
 	return nil
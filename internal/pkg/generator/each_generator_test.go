@@ -1,9 +1,12 @@
 package generator
 
 import (
+	"maps"
+	"strings"
 	"testing"
 
 	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -185,3 +188,152 @@ func TestGenerateEachColumnWithLowerCount(t *testing.T) {
 	}
 	assert.Equal(t, exp, files["person_event"])
 }
+
+func TestGenerateEachColumnSampled(t *testing.T) {
+	files := map[string]model.CSVFile{
+		"person": {
+			Name:   "person",
+			Header: []string{"id"},
+			Lines:  [][]string{{"p1", "p2", "p3"}},
+		},
+		"event": {
+			Name:   "event",
+			Header: []string{"id"},
+			Lines:  [][]string{{"e1", "e2", "e3", "e4"}},
+		},
+	}
+
+	each := func(table, sample string) model.Column {
+		return model.Column{
+			Name: table + "_id",
+			Type: "each",
+			Generator: model.ToRawMessage(t, EachGenerator{
+				Table:  table,
+				Column: "id",
+				Sample: sample,
+			}),
+		}
+	}
+
+	cases := []struct {
+		name    string
+		count   int
+		columns []model.Column
+		expRows int
+		expErr  string
+	}{
+		{
+			name:    "subset",
+			count:   5,
+			columns: []model.Column{each("person", "random"), each("event", "")},
+			expRows: 5,
+		},
+		{
+			name:    "every combination",
+			columns: []model.Column{each("person", "random"), each("event", "random")},
+			expRows: 12,
+		},
+		{
+			name:    "more than every combination",
+			count:   13,
+			columns: []model.Column{each("person", "random"), each("event", "")},
+			expErr:  `can't sample 13 combinations of each columns for "person_event", as there are only 12`,
+		},
+		{
+			name:    "different samples",
+			columns: []model.Column{each("person", "random"), each("event", "sequential")},
+			expErr:  `each columns of "person_event" have different samples: "random" and "sequential"`,
+		},
+		{
+			name:    "invalid sample",
+			columns: []model.Column{each("person", "shuffled"), each("event", "")},
+			expErr:  `invalid sample "shuffled" for each columns of "person_event"`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			table := model.Table{Name: "person_event", Count: c.count, Columns: c.columns}
+			files := maps.Clone(files)
+
+			err := EachGenerator{}.Generate(table, files)
+			if c.expErr != "" {
+				assert.EqualError(t, err, c.expErr)
+				return
+			}
+			assert.NoError(t, err)
+
+			lines := files["person_event"].Lines
+			assert.Len(t, lines[0], c.expRows)
+
+			combinations := lo.Uniq(lo.Map(Transpose(lines), func(row []string, _ int) string {
+				return strings.Join(row, ",")
+			}))
+			assert.Len(t, combinations, c.expRows)
+		})
+	}
+}
+
+func TestGenerateEachColumnErrorPosition(t *testing.T) {
+	table := model.Table{
+		Name: "person_event",
+		Columns: []model.Column{
+			{
+				Name:      "person_id",
+				Type:      "each",
+				Generator: model.ToRawMessage(t, map[string]any{"table": "person", "column": "id"}),
+				Position:  model.Position{File: "config.yaml", Line: 12, Column: 9},
+			},
+		},
+	}
+
+	err := EachGenerator{}.Generate(table, map[string]model.CSVFile{})
+	assert.EqualError(t, err, `config.yaml:12:9: table "person" not found`)
+}
+
+func TestGenerateEachColumnScaled(t *testing.T) {
+	cases := []struct {
+		name    string
+		scale   float64
+		fixed   bool
+		expRows int
+	}{
+		{name: "scaled down", scale: 0.5, expRows: 2},
+		{name: "not scaled up past every combination", scale: 2, expRows: 4},
+		{name: "fixed", scale: 0.5, fixed: true, expRows: 4},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			SetScale(c.scale)
+			defer SetScale(1)
+
+			table := model.Table{
+				Name:  "person_event",
+				Fixed: c.fixed,
+				Columns: []model.Column{
+					{
+						Name:      "person_id",
+						Type:      "each",
+						Generator: model.ToRawMessage(t, EachGenerator{Table: "person", Column: "id"}),
+					},
+					{
+						Name:      "event_id",
+						Type:      "each",
+						Generator: model.ToRawMessage(t, EachGenerator{Table: "event", Column: "id"}),
+					},
+				},
+			}
+
+			files := map[string]model.CSVFile{
+				"person": {Name: "person", Header: []string{"id"}, Lines: [][]string{{"p1", "p2"}}},
+				"event":  {Name: "event", Header: []string{"id"}, Lines: [][]string{{"e1", "e2"}}},
+			}
+
+			if err := (EachGenerator{}).Generate(table, files); err != nil {
+				t.Fatalf("error generating columns: %v", err)
+			}
+			assert.Len(t, files["person_event"].Lines[0], c.expRows)
+		})
+	}
+}
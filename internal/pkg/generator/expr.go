@@ -3,25 +3,55 @@ package generator
 import (
 	"crypto/sha256"
 	"fmt"
+	"maps"
 	"math"
-	"math/rand"
+	"math/rand/v2"
 	"reflect"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/alpeb/go-finance/fin"
 	"github.com/brianvoe/gofakeit/v7"
 	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/codingconcepts/dg/internal/pkg/random"
 	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
 	"github.com/gosimple/slug"
-	"github.com/martinusso/go-docs/cnpj"
-	"github.com/martinusso/go-docs/cpf"
 	"github.com/samber/lo"
 )
 
+// vars holds the config's vars, which expressions read from vars.
+var vars = map[string]any{}
+
+// SetVars sets the vars that expressions can read from vars (e.g.
+// vars.rows).
+func SetVars(v map[string]any) {
+	vars = v
+	if vars == nil {
+		vars = map[string]any{}
+	}
+}
+
 type ExprContext struct {
 	Files  map[string]model.CSVFile
 	Format string
+	Rand   *rand.Rand
+
+	// env holds the functions available to expressions and programs holds
+	// compiled expressions, both of which are built on first use and reused
+	// for every row.
+	env      map[string]any
+	programs map[string]*vm.Program
+}
+
+// rng returns the context's random source, creating one from the root
+// source on first use.
+func (ec *ExprContext) rng() *rand.Rand {
+	if ec.Rand == nil {
+		ec.Rand = random.New()
+	}
+	return ec.Rand
 }
 
 func (ec *ExprContext) mergeEnv(env map[string]any, record map[string]any) error {
@@ -34,10 +64,20 @@ func (ec *ExprContext) mergeEnv(env map[string]any, record map[string]any) error
 	return nil
 }
 
+// makeEnv returns an environment holding the functions available to
+// expressions, to which the caller can add the values of the current row.
 func (ec *ExprContext) makeEnv() map[string]any {
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	faker := initGofakeit()
+	if ec.env == nil {
+		ec.env = ec.functions()
+	}
+	return maps.Clone(ec.env)
+}
+
+func (ec *ExprContext) functions() map[string]any {
+	r := ec.rng()
+	faker := initGofakeit(r)
 	env := map[string]any{
+		"vars": vars,
 		"match": func(sourceTable string, sourceColumn string, sourceValue string, matchColumn string) (any, error) {
 			value, err := ec.searchFile(sourceTable, sourceColumn, sourceValue, matchColumn)
 			if err != nil {
@@ -72,15 +112,15 @@ func (ec *ExprContext) makeEnv() map[string]any {
 		},
 		"rand": func(n int) int {
 			if n < 0 {
-				return r.Intn(n*-1) * -1
+				return r.IntN(n*-1) * -1
 			}
-			return r.Intn(n)
+			return r.IntN(n)
 		},
 		"randr": func(min int, max int) int {
 			if min > max {
 				min, max = max, min
 			}
-			return r.Intn(max-min+1) + min
+			return r.IntN(max-min+1) + min
 		},
 		"get_record": func(table string, line int) (map[string]any, error) {
 			return model.GetRecord(table, line, ec.Files), nil
@@ -187,13 +227,42 @@ func (ec *ExprContext) makeEnv() map[string]any {
 }
 
 func (ec *ExprContext) evaluate(expression string, env any) (any, error) {
-	output, err := expr.Eval(expression, env)
+	program, err := ec.compile(expression)
+	if err != nil {
+		return nil, fmt.Errorf("error evaluating expression: %w", err)
+	}
+
+	output, err := expr.Run(program, env)
 	if err != nil {
 		return nil, fmt.Errorf("error evaluating expression: %w", err)
 	}
 	return output, nil
 }
 
+// compile returns the compiled form of an expression, compiling it the first
+// time it's seen. Expressions are type checked against the functions in the
+// environment, while row values are left to be resolved when they're run.
+func (ec *ExprContext) compile(expression string) (*vm.Program, error) {
+	if program, ok := ec.programs[expression]; ok {
+		return program, nil
+	}
+
+	if ec.env == nil {
+		ec.env = ec.functions()
+	}
+
+	program, err := expr.Compile(expression, expr.Env(ec.env), expr.AllowUndefinedVariables())
+	if err != nil {
+		return nil, err
+	}
+
+	if ec.programs == nil {
+		ec.programs = map[string]*vm.Program{}
+	}
+	ec.programs[expression] = program
+	return program, nil
+}
+
 func (ec *ExprContext) searchFile(sourceName string, sourceColumn, sourceValue, matchColumn string) (string, error) {
 	sourceFile, exists := ec.Files[sourceName]
 	if !exists {
@@ -202,52 +271,58 @@ func (ec *ExprContext) searchFile(sourceName string, sourceColumn, sourceValue,
 	return ec.searchValue(sourceFile, sourceColumn, sourceValue, matchColumn)
 }
 
+// index returns the index of a file's column, caching it with the context's
+// copy of the file where there is one.
+func (ec *ExprContext) index(file model.CSVFile, column string) (map[string][]int, bool) {
+	if _, ok := ec.Files[file.Name]; ok {
+		return model.GetIndex(file.Name, column, ec.Files)
+	}
+	return file.Index(column)
+}
+
 func (ec *ExprContext) searchValue(sourceFile model.CSVFile, sourceColumn, sourceValue, matchColumn string) (string, error) {
-	sourceColumnIndex := lo.IndexOf(sourceFile.Header, sourceColumn)
-	matchColumnIndex := lo.IndexOf(sourceFile.Header, matchColumn)
-	if sourceColumnIndex == -1 {
+	index, ok := ec.index(sourceFile, sourceColumn)
+	if !ok {
 		return "", fmt.Errorf("column not found: %s in %s", sourceColumn, sourceFile.Name)
 	}
+	matchColumnIndex := lo.IndexOf(sourceFile.Header, matchColumn)
 	if matchColumnIndex == -1 {
 		return "", fmt.Errorf("column not found: %s in %s", matchColumn, sourceFile.Name)
 	}
-	_, index, found := lo.FindIndexOf(sourceFile.Lines[sourceColumnIndex], func(item string) bool {
-		return item == sourceValue
-	})
-	if found {
-		return sourceFile.Lines[matchColumnIndex][index], nil
+	if rows := index[sourceValue]; len(rows) > 0 {
+		return sourceFile.Lines[matchColumnIndex][rows[0]], nil
 	}
 
 	return "", fmt.Errorf("value not found for %s in column %s", sourceValue, sourceColumn)
 }
 
+// searchRecord returns the first record whose sourceColumn holds matchValue
+// and satisfies the predicate (if given). The record's rows_skipped field
+// holds the number of matching records that didn't satisfy the predicate.
 func (ec *ExprContext) searchRecord(sourceFile model.CSVFile, sourceColumn, matchValue string, predicate string) (map[string]any, error) {
-	sourceColumnIndex := lo.IndexOf(sourceFile.Header, sourceColumn)
-	if sourceColumnIndex == -1 {
+	index, ok := ec.index(sourceFile, sourceColumn)
+	if !ok {
 		return map[string]any{}, fmt.Errorf("column not found: %s in %s", sourceColumn, sourceFile.Name)
 	}
-	columnValues := sourceFile.Lines[sourceColumnIndex]
 	skipped := 0
-	for i, item := range columnValues {
-		if item == matchValue {
-			record := sourceFile.GetRecord(i)
-			record[model.ROWS_SKIPPED] = skipped
-			if predicate != "" {
-				env := ec.makeEnv()
-				if err := ec.mergeEnv(env, record); err != nil {
-					return nil, err
-				}
-				match, err := ec.evaluate(predicate, env)
-				if err != nil {
-					return nil, err
-				}
-				if !ec.AnyToBool(match) {
-					skipped++
-					continue
-				}
+	for _, i := range index[matchValue] {
+		record := sourceFile.GetRecord(i)
+		record[model.ROWS_SKIPPED] = skipped
+		if predicate != "" {
+			env := ec.makeEnv()
+			if err := ec.mergeEnv(env, record); err != nil {
+				return nil, err
+			}
+			match, err := ec.evaluate(predicate, env)
+			if err != nil {
+				return nil, err
+			}
+			if !ec.AnyToBool(match) {
+				skipped++
+				continue
 			}
-			return record, nil
 		}
+		return record, nil
 	}
 
 	return map[string]any{}, fmt.Errorf("value %s not found in column %s", matchValue, sourceColumn)
@@ -306,10 +381,21 @@ func (ec *ExprContext) AnyToBool(value any) bool {
 	}
 }
 
-func initGofakeit() *gofakeit.Faker {
+// registerLookups ensures dg's custom gofakeit lookups are only registered
+// once.
+var registerLookups sync.Once
+
+// initGofakeit registers dg's custom gofakeit lookups and returns a faker that
+// draws from the given random source.
+func initGofakeit(r *rand.Rand) *gofakeit.Faker {
+	registerLookups.Do(addLookups)
+	return gofakeit.NewFaker(r, false)
+}
+
+func addLookups() {
 	cpfInfo := gofakeit.Info{
 		Generate: func(f *gofakeit.Faker, m *gofakeit.MapParams, info *gofakeit.Info) (any, error) {
-			return cpf.Generate(), nil
+			return generateCPF(f), nil
 		},
 	}
 	gofakeit.AddFuncLookup("Cpf", cpfInfo)
@@ -317,7 +403,7 @@ func initGofakeit() *gofakeit.Faker {
 
 	cpnjInfo := gofakeit.Info{
 		Generate: func(f *gofakeit.Faker, m *gofakeit.MapParams, info *gofakeit.Info) (any, error) {
-			return cnpj.Generate(), nil
+			return generateCNPJ(f), nil
 		},
 	}
 	gofakeit.AddFuncLookup("Cnpj", cpnjInfo)
@@ -326,12 +412,9 @@ func initGofakeit() *gofakeit.Faker {
 	regexInfo := gofakeit.Info{
 		Generate: func(f *gofakeit.Faker, m *gofakeit.MapParams, info *gofakeit.Info) (any, error) {
 			pattern := m.Get("")
-			return gofakeit.Regex(pattern[0]), nil
+			return f.Regex(pattern[0]), nil
 		},
 	}
 	gofakeit.AddFuncLookup("regex", regexInfo)
 	gofakeit.AddFuncLookup("regex", regexInfo)
-
-	faker := gofakeit.New(0)
-	return faker
 }
@@ -2,6 +2,7 @@ package generator
 
 import (
 	"fmt"
+	"math/rand/v2"
 	"reflect"
 
 	"github.com/codingconcepts/dg/internal/pkg/model"
@@ -23,20 +24,52 @@ func (g ExprGenerator) Generate(t model.Table, c model.Column, files map[string]
 			return len(a) > len(b)
 		}))
 	}
-	ec := &ExprContext{Files: files, Format: g.Format}
+
+	names, err := inspectExpression(g.Expression)
+	if err != nil {
+		return fmt.Errorf("parsing expression: %w", err)
+	}
+
+	block := func(r *rand.Rand, row, n int) ([]string, error) {
+		ec := &ExprContext{Files: files, Format: g.Format, Rand: r}
+		return g.evaluateRows(ec, t, files, row, n, t.Count)
+	}
+
+	// Expressions that can read other rows of the table are evaluated in
+	// one go, as blocks of rows can't be evaluated in any order.
+	var lines []string
+	if names.dynamicTables || lo.Contains(names.tables, t.Name) {
+		lines, err = block(columnRand(t, c), 0, t.Count)
+	} else {
+		if workers > 1 {
+			model.CacheIndexes(files)
+		}
+		lines, err = runBlocks(t, c, t.Count, t.Count, block)
+	}
+	if err != nil {
+		return err
+	}
+
+	AddTable(t, c.Name, lines, files)
+	return nil
+}
+
+// evaluateRows evaluates the expression for n rows of a table, starting at
+// the given row, and returns up to limit values.
+func (g ExprGenerator) evaluateRows(ec *ExprContext, t model.Table, files map[string]model.CSVFile, row, n, limit int) ([]string, error) {
 	var lines []string
-	for i := 0; i < t.Count; i++ {
-		if len(lines) == t.Count {
+	for i := row; i < row+n; i++ {
+		if len(lines) == limit {
 			break
 		}
 		record := model.GetRecord(t.Name, i, files)
 		env := ec.makeEnv()
 		if err := ec.mergeEnv(env, record); err != nil {
-			return err
+			return nil, err
 		}
 		result, err := ec.evaluate(g.Expression, env)
 		if err != nil {
-			return fmt.Errorf("error evaluating expression %w", err)
+			return nil, fmt.Errorf("error evaluating expression %w", err)
 		}
 		items := reflect.ValueOf(result)
 		if items.Kind() == reflect.Array || items.Kind() == reflect.Slice {
@@ -44,7 +77,7 @@ func (g ExprGenerator) Generate(t model.Table, c model.Column, files map[string]
 				item := items.Index(j)
 				line := ec.AnyToString(item.Interface())
 				lines = append(lines, line)
-				if len(lines) == t.Count {
+				if len(lines) == limit {
 					break
 				}
 			}
@@ -53,6 +86,5 @@ func (g ExprGenerator) Generate(t model.Table, c model.Column, files map[string]
 			lines = append(lines, line)
 		}
 	}
-	AddTable(t, c.Name, lines, files)
-	return nil
+	return lines, nil
 }
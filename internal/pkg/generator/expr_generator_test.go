@@ -1,6 +1,7 @@
 package generator
 
 import (
+	"maps"
 	"strconv"
 	"strings"
 	"testing"
@@ -466,3 +467,91 @@ func TestGeneratorExprMatchFunction(t *testing.T) {
 		})
 	}
 }
+
+func BenchmarkGenerateExprColumn(b *testing.B) {
+	table := model.Table{Name: "table", Count: 1000}
+	files := map[string]model.CSVFile{
+		"table": {
+			Name:   "table",
+			Header: []string{"id"},
+			Lines:  [][]string{lo.Times(1000, strconv.Itoa)},
+		},
+	}
+	g := ExprGenerator{Expression: "int(id) * 2"}
+
+	for i := 0; i < b.N; i++ {
+		files := maps.Clone(files)
+		assert.NoError(b, g.Generate(table, model.Column{Name: "doubled"}, files))
+	}
+}
+
+func TestGeneratorExprWorkers(t *testing.T) {
+	defer SetWorkers(1)
+
+	count := blockRows*2 + 1
+	ids := make([]string, count)
+	for i := range ids {
+		ids[i] = strconv.Itoa(i + 1)
+	}
+
+	cases := []struct {
+		name       string
+		expression string
+	}{
+		{name: "row independent", expression: "int(id) * 2 + rand(100)"},
+		{name: "reads other rows", expression: "get_record('person', int(id) % 7).id + string(rand(100))"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			table := model.Table{Name: "person", Count: count}
+			column := model.Column{Name: "value"}
+			g := ExprGenerator{Expression: c.expression}
+
+			generate := func(workers int) []string {
+				SetWorkers(workers)
+				files := map[string]model.CSVFile{
+					"person": {Name: "person", Header: []string{"id"}, Lines: [][]string{ids}},
+				}
+				assert.NoError(t, g.Generate(table, column, files))
+				return files["person"].Lines[1]
+			}
+
+			sequential := generate(1)
+			assert.Len(t, sequential, count)
+			assert.Equal(t, sequential, generate(4))
+		})
+	}
+}
+
+func TestGeneratorExprVars(t *testing.T) {
+	SetVars(map[string]any{"multiplier": 3, "region": "eu"})
+	defer SetVars(nil)
+
+	table := model.Table{
+		Name:  "table",
+		Count: 2,
+	}
+
+	column := model.Column{
+		Name: "column",
+	}
+
+	files := map[string]model.CSVFile{
+		"table": {
+			Name:   "table",
+			Header: []string{"id"},
+			Lines: [][]string{
+				{"1", "2"},
+			},
+		},
+	}
+
+	g := ExprGenerator{
+		Expression: "vars.region + '-' + string(int(id) * vars.multiplier)",
+	}
+	if err := g.Generate(table, column, files); err != nil {
+		t.Fatalf("error generating column: %v", err)
+	}
+	assert.Equal(t, []string{"eu-3", "eu-6"}, files["table"].Lines[1])
+}
@@ -23,10 +23,10 @@ func (g ForeignKeyGenerator) Generate(t model.Table, files map[string]model.CSVF
 	for _, c := range cols {
 		var fkCol ForeignKeyGenerator
 		if err := c.Generator.UnmarshalFunc(&fkCol); err != nil {
-			return fmt.Errorf("parsing fk process for %s.%s: %w", t.Name, c.Name, err)
+			return c.Position.Wrap(fmt.Errorf("parsing fk process for %s.%s: %w", t.Name, c.Name, err))
 		}
 		if err := fkCol.generate(t, c, files); err != nil {
-			return fmt.Errorf("generating fk columns: %w", err)
+			return c.Position.Wrap(fmt.Errorf("generating fk columns: %w", err))
 		}
 	}
 
@@ -57,12 +57,12 @@ func (g ForeignKeyGenerator) generate(t model.Table, col model.Column, files map
 		return fmt.Errorf("no values found in referenced column %q of table %q", refColumn, refTable)
 	}
 
+	ec := &ExprContext{Files: files, Rand: columnRand(t, col)}
 	var lines []string
 	rows := 0
 	skipped := 0
 	for i, val := range refValues {
 		repeat := 1
-		ec := &ExprContext{Files: files}
 		record := model.GetRecord(t.Name, i, files)
 		env := ec.makeEnv()
 		if err := ec.mergeEnv(env, record); err != nil {
@@ -93,6 +93,9 @@ func (g ForeignKeyGenerator) generate(t model.Table, col model.Column, files map
 			if !ok {
 				return fmt.Errorf("cannot cast value to int: %s", output)
 			}
+			if !t.Fixed {
+				repeat = model.ScaleCount(repeat, scale)
+			}
 		}
 		for j := 0; j < repeat; j++ {
 			lines = append(lines, val)
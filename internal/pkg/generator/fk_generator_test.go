@@ -191,3 +191,39 @@ func TestFKGenerator_Generate(t *testing.T) {
 		})
 	}
 }
+
+func TestFKGeneratorScaledRepeat(t *testing.T) {
+	SetScale(2)
+	defer SetScale(1)
+
+	files := func() map[string]model.CSVFile {
+		return map[string]model.CSVFile{
+			"orders": {
+				Header: []string{"order_id", "item_count"},
+				Lines: [][]string{
+					{"A", "B"},
+					{"2", "1"},
+				},
+			},
+		}
+	}
+
+	g := ForeignKeyGenerator{
+		Table:  "orders",
+		Column: "order_id",
+		Repeat: "int(parent.item_count)",
+	}
+	column := model.Column{Name: "order_id"}
+
+	scaled := files()
+	if err := g.generate(model.Table{Name: "order_items"}, column, scaled); err != nil {
+		t.Fatalf("error generating column: %v", err)
+	}
+	assert.Equal(t, []string{"A", "A", "A", "A", "B", "B"}, scaled["order_items"].Lines[0])
+
+	fixed := files()
+	if err := g.generate(model.Table{Name: "order_items", Fixed: true}, column, fixed); err != nil {
+		t.Fatalf("error generating column: %v", err)
+	}
+	assert.Equal(t, []string{"A", "A", "B"}, fixed["order_items"].Lines[0])
+}
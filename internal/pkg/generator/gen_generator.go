@@ -2,15 +2,13 @@ package generator
 
 import (
 	"fmt"
+	"math/rand/v2"
 	"strings"
 	"text/template"
 
 	"github.com/brianvoe/gofakeit/v7"
 	"github.com/codingconcepts/dg/internal/pkg/model"
-	"github.com/codingconcepts/dg/internal/pkg/random"
 	"github.com/lucasjones/reggen"
-	"github.com/martinusso/go-docs/cnpj"
-	"github.com/martinusso/go-docs/cpf"
 	"github.com/samber/lo"
 )
 
@@ -25,6 +23,7 @@ type GenGenerator struct {
 	patternGenerator *reggen.Generator
 	templateOptions  gofakeit.TemplateOptions
 	faker            *gofakeit.Faker
+	rand             *rand.Rand
 }
 
 func (g GenGenerator) GetFormat() string {
@@ -33,53 +32,62 @@ func (g GenGenerator) GetFormat() string {
 
 // Generate random data for a given column.
 func (g GenGenerator) Generate(t model.Table, c model.Column, files map[string]model.CSVFile) error {
-	if g.Value == "" && g.Pattern == "" && g.Template == "" {
-		return fmt.Errorf("gen must have either 'value', 'pattern' or 'template'")
-	}
-
 	if t.Count == 0 {
 		t.Count = len(lo.MaxBy(files[t.Name].Lines, func(a, b []string) bool {
 			return len(a) > len(b)
 		}))
 	}
 
+	lines, err := generateBlocks(t, c, t.Count, g.values)
+	if err != nil {
+		return err
+	}
+
+	AddTable(t, c.Name, lines, files)
+	return nil
+}
+
+// values returns a function that generates a block of the column's values in
+// turn.
+func (g GenGenerator) values(r *rand.Rand, _ int) (valueFunc, error) {
+	if g.Value == "" && g.Pattern == "" && g.Template == "" {
+		return nil, fmt.Errorf("gen must have either 'value', 'pattern' or 'template'")
+	}
+
+	g.rand = r
+	g.faker = initGofakeit(g.rand)
+
 	if g.Pattern != "" {
 		var err error
 		if g.patternGenerator, err = reggen.NewGenerator(g.Pattern); err != nil {
-			return fmt.Errorf("creating regex generator: %w", err)
+			return nil, fmt.Errorf("creating regex generator: %w", err)
 		}
+		g.patternGenerator.SetSeed(g.rand.Int64())
 	}
 
-	g.faker = initGofakeit()
 	if g.Template != "" {
+		cpf := func() string { return generateCPF(g.faker) }
+		cnpj := func() string { return generateCNPJ(g.faker) }
 		g.templateOptions = gofakeit.TemplateOptions{
 			Funcs: template.FuncMap{
-				"cpf":  cpf.Generate,
-				"Cpf":  cpf.Generate,
-				"CPF":  cpf.Generate,
-				"cnpj": cnpj.Generate,
-				"Cnpj": cnpj.Generate,
-				"CNPJ": cnpj.Generate,
+				"cpf":  cpf,
+				"Cpf":  cpf,
+				"CPF":  cpf,
+				"cnpj": cnpj,
+				"Cnpj": cnpj,
+				"CNPJ": cnpj,
 			},
 		}
-		if _, err := gofakeit.Template(g.Template, &g.templateOptions); err != nil {
-			return fmt.Errorf("parsing template: %w", err)
+		if _, err := g.faker.Template(g.Template, &g.templateOptions); err != nil {
+			return nil, fmt.Errorf("parsing template: %w", err)
 		}
 	}
 
-	var lines []string
-	for i := 0; i < t.Count; i++ {
-		s := g.generate()
-		lines = append(lines, s)
-	}
-
-	AddTable(t, c.Name, lines, files)
-	return nil
+	return g.generate, nil
 }
 
 func (pg GenGenerator) generate() string {
-	r := random.Intn(100)
-	if r < pg.NullPercentage {
+	if pg.rand.IntN(100) < pg.NullPercentage {
 		return ""
 	}
 
@@ -99,13 +107,14 @@ func (pg GenGenerator) generate() string {
 
 	// Look for quick single-replacements.
 	if v, ok := replacements[s]; ok {
-		return formatValue(pg, v())
+		return formatValue(pg, v(pg.faker))
 	}
 
-	// Process multipe-replacements.
-	for k, v := range replacements {
+	// Process multipe-replacements, in a stable order so that seeded runs are
+	// reproducible.
+	for _, k := range replacementKeys {
 		if strings.Contains(s, k) {
-			valueStr := formatValue(pg, v())
+			valueStr := formatValue(pg, replacements[k](pg.faker))
 			s = strings.ReplaceAll(s, k, valueStr)
 		}
 	}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/codingconcepts/dg/internal/pkg/random"
 	"github.com/lucasjones/reggen"
 	"github.com/martinusso/go-docs/cnpj"
 	"github.com/martinusso/go-docs/cpf"
@@ -141,9 +142,38 @@ func BenchmarkGeneratePattern(b *testing.B) {
 	g := GenGenerator{
 		Pattern:          pattern,
 		patternGenerator: patternGenerator,
+		rand:             random.New(),
 	}
 
 	for i := 0; i < b.N; i++ {
 		g.generate()
 	}
 }
+
+func TestGenerateGenColumnSeeded(t *testing.T) {
+	cases := []struct {
+		name string
+		g    GenGenerator
+	}{
+		{name: "value", g: GenGenerator{Value: "${first_name} ${last_name} ${cpf}"}},
+		{name: "pattern", g: GenGenerator{Pattern: `[a-z]{3}-\d{3}`}},
+		{name: "template", g: GenGenerator{Template: "{{FirstName}} {{cnpj}}"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			table := model.Table{Name: "table", Count: 10}
+			column := model.Column{Name: "col"}
+
+			random.Seed(42)
+			first := map[string]model.CSVFile{}
+			assert.NoError(t, c.g.Generate(table, column, first))
+
+			random.Seed(42)
+			second := map[string]model.CSVFile{}
+			assert.NoError(t, c.g.Generate(table, column, second))
+
+			assert.Equal(t, first["table"].Lines, second["table"].Lines)
+		})
+	}
+}
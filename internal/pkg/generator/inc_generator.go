@@ -1,6 +1,8 @@
 package generator
 
 import (
+	"math/rand/v2"
+
 	"github.com/codingconcepts/dg/internal/pkg/model"
 	"github.com/samber/lo"
 )
@@ -23,11 +25,22 @@ func (g IncGenerator) Generate(t model.Table, c model.Column, files map[string]m
 		}))
 	}
 
-	var line []string
-	for i := 0; i < t.Count; i++ {
-		line = append(line, formatValue(g, g.Start+i))
+	line, err := generateBlocks(t, c, t.Count, g.values)
+	if err != nil {
+		return err
 	}
 
 	AddTable(t, c.Name, line, files)
 	return nil
 }
+
+// values returns a function that generates a block of the column's values in
+// turn, starting at the given row.
+func (g IncGenerator) values(_ *rand.Rand, row int) (valueFunc, error) {
+	i := row
+	return func() string {
+		value := formatValue(g, g.Start+i)
+		i++
+		return value
+	}, nil
+}
@@ -44,11 +44,12 @@ func (g LookupGenerator) Generate(t model.Table, c model.Column, files map[strin
 		return fmt.Errorf("not enough values in base table: %d values, need %d", count, t.Count)
 	}
 	re := regexp.MustCompile(`value \S+ not found in column \S+`)
+	ec := &ExprContext{Files: files, Rand: columnRand(t, c)}
 	var lines []string
 	rows := 0
 	for rows < t.Count {
 		matchValue := baseTable.Lines[baseColumnIndex][rows]
-		values, err := g.generate(matchValue, g.LookupTables, files)
+		values, err := g.generate(ec, matchValue, g.LookupTables, files)
 		if err == nil || (re.MatchString(err.Error()) && g.IgnoreMissing) {
 			if len(values) == 0 {
 				values = []string{""}
@@ -63,11 +64,10 @@ func (g LookupGenerator) Generate(t model.Table, c model.Column, files map[strin
 	return nil
 }
 
-func (g LookupGenerator) generate(matchValue string, lookupTables []LookupTable, files map[string]model.CSVFile) ([]string, error) {
+func (g LookupGenerator) generate(ec *ExprContext, matchValue string, lookupTables []LookupTable, files map[string]model.CSVFile) ([]string, error) {
 	if matchValue == "" {
 		return []string{}, fmt.Errorf("match_column is required")
 	}
-	ec := &ExprContext{Files: files}
 	values := []string{}
 	value := matchValue
 	env := make(map[string]any)
@@ -243,6 +243,22 @@ func TestExprContext_Evaluate(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, 10, result)
 	})
+
+	t.Run("Compiled once", func(t *testing.T) {
+		ec := &ExprContext{}
+		for i := 1; i <= 3; i++ {
+			result, err := ec.evaluate("x + 1", map[string]any{"x": i})
+			assert.NoError(t, err)
+			assert.Equal(t, i+1, result)
+		}
+		assert.Len(t, ec.programs, 1)
+	})
+
+	t.Run("Type checked against functions", func(t *testing.T) {
+		ec := &ExprContext{}
+		_, err := ec.evaluate("pad('a')", ec.makeEnv())
+		assert.ErrorContains(t, err, "not enough arguments to call pad")
+	})
 }
 
 func TestExprContext_AnyToString(t *testing.T) {
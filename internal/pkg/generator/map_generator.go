@@ -22,7 +22,7 @@ func (g MapGenerator) Generate(t model.Table, col model.Column, files map[string
 	if !ok {
 		return fmt.Errorf("referenced table %s not found", g.Table)
 	}
-	ec := &ExprContext{Files: files, Format: g.Format}
+	ec := &ExprContext{Files: files, Format: g.Format, Rand: columnRand(t, col)}
 	columnValues := refFile.GetColumnValues(g.Column)
 	countValues := lo.CountValues(columnValues)
 	indexValues := make(map[string]int, len(countValues))
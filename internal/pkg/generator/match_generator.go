@@ -19,21 +19,20 @@ type MatchGenerator struct {
 // Generate matches values from a previously generated table and inserts values
 // into a new table where match is found.
 func (g MatchGenerator) Generate(t model.Table, c model.Column, files map[string]model.CSVFile) error {
-	sourceTable, ok := files[g.SourceTable]
-	if !ok {
+	if _, ok := files[g.SourceTable]; !ok {
 		return fmt.Errorf("missing source table %q for match lookup", g.SourceTable)
 	}
 
-	sourceColumnIndex := lo.IndexOf(sourceTable.Header, g.SourceColumn)
-	sourceColumn := sourceTable.Lines[sourceColumnIndex]
-
-	valueColumnIndex := lo.IndexOf(sourceTable.Header, g.SourceValue)
-	valueColumn := sourceTable.Lines[valueColumnIndex]
+	sourceIndex, ok := model.GetIndex(g.SourceTable, g.SourceColumn, files)
+	if !ok {
+		return fmt.Errorf("missing source column %q in table %q", g.SourceColumn, g.SourceTable)
+	}
 
-	sourceMap := map[string]string{}
-	for i := 0; i < len(sourceColumn); i++ {
-		sourceMap[sourceColumn[i]] = valueColumn[i]
+	sourceTable := files[g.SourceTable]
+	if !lo.Contains(sourceTable.Header, g.SourceValue) {
+		return fmt.Errorf("missing source value column %q in table %q", g.SourceValue, g.SourceTable)
 	}
+	valueColumn := sourceTable.GetColumnValues(g.SourceValue)
 
 	matchTable, ok := files[t.Name]
 	if !ok {
@@ -51,10 +50,12 @@ func (g MatchGenerator) Generate(t model.Table, c model.Column, files map[string
 
 	matchColumn := matchTable.Lines[matchColumnIndex]
 
+	// Where a value appears more than once in the source column, the last
+	// occurrence is used.
 	lines := make([]string, len(matchColumn))
 	for i, matchC := range matchColumn {
-		if sourceValue, ok := sourceMap[matchC]; ok {
-			lines[i] = sourceValue
+		if rows := sourceIndex[matchC]; len(rows) > 0 {
+			lines[i] = valueColumn[rows[len(rows)-1]]
 		}
 	}
 
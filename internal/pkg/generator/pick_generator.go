@@ -2,6 +2,7 @@ package generator
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/codingconcepts/dg/internal/pkg/model"
 	"github.com/samber/lo"
@@ -44,23 +45,28 @@ func (g PickGenerator) Generate(t model.Table, col model.Column, files map[strin
 		t.Count = len(matchColumn)
 	}
 
+	sourceIndex, _ := model.GetIndex(g.SourceTable, g.SourceColumn, files)
+	sourceValues := sourceFile.Lines[sourceValueIndex]
+
 	var lines []string
 	usedValues := make(map[string]bool)
 	lastIndexes := make(map[string]int)
+	nextUnused := make(map[string]int)
 	lastMatchIndex := 0
 	for i := 0; i < t.Count; i++ {
 		currentMatchIndex := (lastMatchIndex + i) % len(matchColumn)
 		matchValue := matchColumn[currentMatchIndex]
+		rows := sourceIndex[matchValue]
 		var value string
 		var err error
 		if g.Unique {
-			value, err = g.findUnusedValue(sourceFile, sourceColumnIndex, sourceValueIndex, matchValue, usedValues)
+			value, nextUnused[matchValue], err = g.findUnusedValue(sourceValues, rows, matchValue, nextUnused[matchValue], usedValues)
 		} else {
 			lastIndex, ok := lastIndexes[matchValue]
 			if !ok {
 				lastIndex = 0
 			}
-			value, lastIndex, err = g.findNextValue(sourceFile, sourceColumnIndex, sourceValueIndex, matchValue, lastIndex)
+			value, lastIndex, err = g.findNextValue(sourceValues, rows, matchValue, lastIndex)
 			if err == nil {
 				lastIndexes[matchValue] = lastIndex
 			}
@@ -76,33 +82,34 @@ func (g PickGenerator) Generate(t model.Table, col model.Column, files map[strin
 	return nil
 }
 
-func (g PickGenerator) findUnusedValue(sourceFile model.CSVFile, sourceColumnIndex, sourceValueIndex int, matchValue string, usedValues map[string]bool) (string, error) {
-	matched := false
-	for i, sourceValue := range sourceFile.Lines[sourceColumnIndex] {
-		if sourceValue == matchValue {
-			matched = true
-			value := sourceFile.Lines[sourceValueIndex][i]
-			if !usedValues[value] {
-				return value, nil
-			}
+// findUnusedValue returns the first value from the given rows that hasn't
+// been used, searching from the position of rows given by next. As values
+// are never unused once used, the position returned can be used to resume
+// the search for the same match value.
+func (g PickGenerator) findUnusedValue(sourceValues []string, rows []int, matchValue string, next int, usedValues map[string]bool) (string, int, error) {
+	for ; next < len(rows); next++ {
+		value := sourceValues[rows[next]]
+		if !usedValues[value] {
+			return value, next, nil
 		}
 	}
-	if matched {
-		return "", fmt.Errorf("no unused value found for match value %s", matchValue)
+	if len(rows) > 0 {
+		return "", next, fmt.Errorf("no unused value found for match value %s", matchValue)
 	}
-	return "", fmt.Errorf("no match found for %s", matchValue)
+	return "", next, fmt.Errorf("no match found for %s", matchValue)
 }
 
-func (g PickGenerator) findNextValue(sourceFile model.CSVFile, sourceColumnIndex, sourceValueIndex int, matchValue string, lastIndex int) (string, int, error) {
-	sourceColumnValues := sourceFile.Lines[sourceColumnIndex]
-	sourceValueValues := sourceFile.Lines[sourceValueIndex]
-
-	for i := 0; i < len(sourceColumnValues); i++ {
-		currentIndex := (lastIndex + i) % len(sourceColumnValues)
-		if sourceColumnValues[currentIndex] == matchValue {
-			return sourceValueValues[currentIndex], currentIndex + 1, nil
-		}
+// findNextValue returns the value of the first of the given rows at or after
+// lastIndex, wrapping around to the first row, along with the index to
+// search from next time.
+func (g PickGenerator) findNextValue(sourceValues []string, rows []int, matchValue string, lastIndex int) (string, int, error) {
+	if len(rows) == 0 {
+		return "", lastIndex, fmt.Errorf("no match found for %s", matchValue)
 	}
 
-	return "", lastIndex, fmt.Errorf("no match found for %s", matchValue)
+	next := sort.SearchInts(rows, lastIndex%len(sourceValues))
+	if next == len(rows) {
+		next = 0
+	}
+	return sourceValues[rows[next]], rows[next] + 1, nil
 }
@@ -159,31 +159,74 @@ func TestPickGenerator_Generate_ErrorCases(t *testing.T) {
 func TestPickGenerator_findUnusedValue(t *testing.T) {
 	generator := PickGenerator{}
 	sourceFile := model.CSVFile{
+		Header: []string{"id", "name", "value"},
 		Lines: [][]string{
 			{"1", "2", "3", "4"},
 			{"Alice", "Bob", "Alice", "Bob"},
 			{"A", "B", "C", "D"},
 		},
 	}
+	index, _ := sourceFile.Index("name")
+	values := sourceFile.Lines[2]
 
 	t.Run("Find unused value", func(t *testing.T) {
 		usedValues := map[string]bool{"A": true}
-		value, err := generator.findUnusedValue(sourceFile, 1, 2, "Alice", usedValues)
+		value, next, err := generator.findUnusedValue(values, index["Alice"], "Alice", 0, usedValues)
+		assert.NoError(t, err)
+		assert.Equal(t, "C", value)
+		assert.Equal(t, 1, next)
+	})
+
+	t.Run("Resume search", func(t *testing.T) {
+		usedValues := map[string]bool{}
+		value, _, err := generator.findUnusedValue(values, index["Alice"], "Alice", 1, usedValues)
 		assert.NoError(t, err)
 		assert.Equal(t, "C", value)
 	})
 
 	t.Run("No unused value found", func(t *testing.T) {
 		usedValues := map[string]bool{"A": true, "B": true, "C": true}
-		_, err := generator.findUnusedValue(sourceFile, 1, 2, "Alice", usedValues)
+		_, _, err := generator.findUnusedValue(values, index["Alice"], "Alice", 0, usedValues)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "no unused value found for match value Alice")
 	})
 
 	t.Run("No match found", func(t *testing.T) {
 		usedValues := map[string]bool{"A": true, "B": true, "C": true}
-		_, err := generator.findUnusedValue(sourceFile, 1, 2, "David", usedValues)
+		_, _, err := generator.findUnusedValue(values, index["David"], "David", 0, usedValues)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "no match found for David")
 	})
 }
+
+func TestPickGenerator_findNextValue(t *testing.T) {
+	generator := PickGenerator{}
+	values := []string{"A", "B", "C", "D"}
+	rows := []int{0, 2}
+
+	cases := []struct {
+		name      string
+		lastIndex int
+		exp       string
+		expNext   int
+	}{
+		{name: "first", lastIndex: 0, exp: "A", expNext: 1},
+		{name: "next", lastIndex: 1, exp: "C", expNext: 3},
+		{name: "wraps", lastIndex: 3, exp: "A", expNext: 1},
+		{name: "wraps at end", lastIndex: 4, exp: "A", expNext: 1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			value, next, err := generator.findNextValue(values, rows, "Alice", c.lastIndex)
+			assert.NoError(t, err)
+			assert.Equal(t, c.exp, value)
+			assert.Equal(t, c.expNext, next)
+		})
+	}
+
+	t.Run("no match found", func(t *testing.T) {
+		_, _, err := generator.findNextValue(values, nil, "David", 0)
+		assert.EqualError(t, err, "no match found for David")
+	})
+}
@@ -2,7 +2,7 @@ package generator
 
 import (
 	"fmt"
-	"math/rand"
+	"math/rand/v2"
 	"strconv"
 	"time"
 
@@ -26,39 +26,46 @@ func (g RandGenerator) Generate(t model.Table, c model.Column, files map[string]
 		count = t.Count
 	}
 
+	lines, err := generateBlocks(t, c, count, g.values)
+	if err != nil {
+		return err
+	}
+
+	AddTable(t, c.Name, lines, files)
+	return nil
+}
+
+// values returns a function that generates a block of the column's values in
+// turn.
+func (g RandGenerator) values(r *rand.Rand, _ int) (valueFunc, error) {
 	switch g.Type {
 	case "date":
-		lines, err := g.generateDateRand(count)
+		next, err := g.dateValues(r)
 		if err != nil {
-			return fmt.Errorf("generating random date: %w", err)
+			return nil, fmt.Errorf("generating random date: %w", err)
 		}
-
-		AddTable(t, c.Name, lines, files)
-		return nil
+		return next, nil
 
 	case "int":
-		lines, err := g.generateIntRand(count)
+		next, err := g.intValues(r)
 		if err != nil {
-			return fmt.Errorf("generating random int: %w", err)
+			return nil, fmt.Errorf("generating random int: %w", err)
 		}
-
-		AddTable(t, c.Name, lines, files)
-		return nil
+		return next, nil
 
 	case "float64":
-		lines, err := g.generateFloatRand(count)
+		next, err := g.floatValues(r)
 		if err != nil {
-			return fmt.Errorf("generating random float64: %w", err)
+			return nil, fmt.Errorf("generating random float64: %w", err)
 		}
+		return next, nil
 
-		AddTable(t, c.Name, lines, files)
-		return nil
 	default:
-		return fmt.Errorf("%q is not a valid random type", g.Type)
+		return nil, fmt.Errorf("%q is not a valid random type", g.Type)
 	}
 }
 
-func (g RandGenerator) generateIntRand(count int) ([]string, error) {
+func (g RandGenerator) intValues(r *rand.Rand) (valueFunc, error) {
 	if g.Format == "" {
 		g.Format = "%v"
 	}
@@ -76,16 +83,14 @@ func (g RandGenerator) generateIntRand(count int) ([]string, error) {
 	if low > high {
 		low, high = high, low
 	}
-	var lines []string
-	for i := 0; i < count; i++ {
-		value := rand.Intn(high-low) + low
-		lines = append(lines, fmt.Sprintf(g.Format, value))
-	}
 
-	return lines, nil
+	return func() string {
+		value := r.IntN(high-low) + low
+		return fmt.Sprintf(g.Format, value)
+	}, nil
 }
 
-func (g RandGenerator) generateFloatRand(count int) ([]string, error) {
+func (g RandGenerator) floatValues(r *rand.Rand) (valueFunc, error) {
 	if g.Format == "" {
 		g.Format = "%v"
 	}
@@ -103,17 +108,14 @@ func (g RandGenerator) generateFloatRand(count int) ([]string, error) {
 	if low > high {
 		low, high = high, low
 	}
-	var lines []string
-	for i := 0; i < count; i++ {
-		value := rand.Float64()*(high-low) + low
-		f := fmt.Sprintf(g.Format, value)
-		lines = append(lines, f)
-	}
 
-	return lines, nil
+	return func() string {
+		value := r.Float64()*(high-low) + low
+		return fmt.Sprintf(g.Format, value)
+	}, nil
 }
 
-func (g RandGenerator) generateDateRand(count int) ([]string, error) {
+func (g RandGenerator) dateValues(r *rand.Rand) (valueFunc, error) {
 	if g.Low == "" || g.High == "" {
 		return nil, fmt.Errorf("'low' and 'high' values must be provided to a date rand generator")
 	}
@@ -132,17 +134,13 @@ func (g RandGenerator) generateDateRand(count int) ([]string, error) {
 	if low.After(high) {
 		low, high = high, low
 	}
-	var lines []string
 	diff := high.Unix() - low.Unix()
 	if diff <= 0 {
 		return nil, fmt.Errorf("no range found between low and high dates")
 	}
 
-	for i := 0; i < count; i++ {
-		randomOffset := rand.Int63n(diff + 1) // +1 to include the high date in the range
-		randomDate := low.Add(time.Duration(randomOffset) * time.Second).Format(g.Format)
-		lines = append(lines, randomDate)
-	}
-
-	return lines, nil
+	return func() string {
+		randomOffset := r.Int64N(diff + 1) // +1 to include the high date in the range
+		return low.Add(time.Duration(randomOffset) * time.Second).Format(g.Format)
+	}, nil
 }
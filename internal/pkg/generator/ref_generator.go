@@ -2,9 +2,9 @@ package generator
 
 import (
 	"fmt"
+	"math/rand/v2"
 
 	"github.com/codingconcepts/dg/internal/pkg/model"
-	"github.com/codingconcepts/dg/internal/pkg/random"
 
 	"github.com/samber/lo"
 )
@@ -24,19 +24,37 @@ func (g RefGenerator) Generate(t model.Table, c model.Column, files map[string]m
 		}))
 	}
 
+	values, err := g.values(files)
+	if err != nil {
+		return err
+	}
+
+	line, err := generateBlocks(t, c, t.Count, values)
+	if err != nil {
+		return err
+	}
+
+	AddTable(t, c.Name, line, files)
+	return nil
+}
+
+// values returns a function that picks blocks of the column's values from the
+// referenced column.
+func (g RefGenerator) values(files map[string]model.CSVFile) (blockFunc, error) {
 	table, ok := files[g.Table]
 	if !ok {
-		return fmt.Errorf("missing table %q for ref lookup", g.Table)
+		return nil, fmt.Errorf("missing table %q for ref lookup", g.Table)
 	}
 
 	colIndex := lo.IndexOf(table.Header, g.Column)
-	column := table.Lines[colIndex]
-
-	var line []string
-	for i := 0; i < t.Count; i++ {
-		line = append(line, column[random.Intn(len(column))])
+	if colIndex == -1 || colIndex >= len(table.Lines) {
+		return nil, fmt.Errorf("missing column %q in table %q for ref lookup", g.Column, g.Table)
 	}
+	column := table.Lines[colIndex]
 
-	AddTable(t, c.Name, line, files)
-	return nil
+	return func(r *rand.Rand, _ int) (valueFunc, error) {
+		return func() string {
+			return column[r.IntN(len(column))]
+		}, nil
+	}, nil
 }
@@ -34,3 +34,19 @@ func TestGenerateRefColumn(t *testing.T) {
 	assert.Equal(t, "ce9af887-37eb-4e08-9790-4f481b0fa594", files["pet"].Lines[0][0])
 	assert.Equal(t, "ce9af887-37eb-4e08-9790-4f481b0fa594", files["pet"].Lines[0][1])
 }
+
+func TestGenerateRefColumnMissingColumn(t *testing.T) {
+	g := RefGenerator{
+		Table:  "person",
+		Column: "person_id",
+	}
+
+	files := map[string]model.CSVFile{
+		"person": {
+			Header: []string{"id"},
+			Lines:  [][]string{{"ce9af887-37eb-4e08-9790-4f481b0fa594"}},
+		},
+	}
+	err := g.Generate(model.Table{Name: "pet", Count: 2}, model.Column{Name: "person_id"}, files)
+	assert.EqualError(t, err, `missing column "person_id" in table "person" for ref lookup`)
+}
@@ -47,7 +47,7 @@ func (g RelDateGenerator) Generate(t model.Table, c model.Column, files map[stri
 	if g.Date == "" || g.Date == "now" {
 		g.Date = "now()"
 	}
-	ec := &ExprContext{Files: files, Format: g.Format}
+	ec := &ExprContext{Files: files, Format: g.Format, Rand: columnRand(t, c)}
 	var lines []string
 	for i := 0; i < t.Count; i++ {
 		record := model.GetRecord(t.Name, i, files)
@@ -92,18 +92,18 @@ func (g RelDateGenerator) Generate(t model.Table, c model.Column, files map[stri
 			}
 		}
 
-		s := g.generate(reference, before, after)
+		s := g.generate(ec.Rand, reference, before, after)
 		lines = append(lines, s)
 	}
 	AddTable(t, c.Name, lines, files)
 	return nil
 }
 
-func (g RelDateGenerator) generate(reference time.Time, before int, after int) string {
+func (g RelDateGenerator) generate(r *rand.Rand, reference time.Time, before int, after int) string {
 	if after > before {
 		after, before = before, after
 	}
-	offset := rand.IntN(before-after+1) + after
+	offset := r.IntN(before-after+1) + after
 	switch g.Unit {
 	case day:
 		return reference.AddDate(0, 0, offset).Format(g.Format)
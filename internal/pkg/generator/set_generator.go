@@ -2,9 +2,9 @@ package generator
 
 import (
 	"fmt"
+	"math/rand/v2"
 
 	"github.com/codingconcepts/dg/internal/pkg/model"
-	"github.com/codingconcepts/dg/internal/pkg/random"
 	"github.com/samber/lo"
 )
 
@@ -16,10 +16,6 @@ type SetGenerator struct {
 
 // Generate selects between a set of values for a given table.
 func (g SetGenerator) Generate(t model.Table, c model.Column, files map[string]model.CSVFile) error {
-	if len(g.Values) == 0 {
-		return fmt.Errorf("no values provided for set generator")
-	}
-
 	count := len(lo.MaxBy(files[t.Name].Lines, func(a, b []string) bool {
 		return len(a) > len(b)
 	}))
@@ -28,24 +24,35 @@ func (g SetGenerator) Generate(t model.Table, c model.Column, files map[string]m
 		count = t.Count
 	}
 
-	var line []string
+	line, err := generateBlocks(t, c, count, g.values)
+	if err != nil {
+		return err
+	}
+
+	AddTable(t, c.Name, line, files)
+	return nil
+}
+
+// values returns a function that selects a block of the column's values in
+// turn.
+func (g SetGenerator) values(r *rand.Rand, _ int) (valueFunc, error) {
+	if len(g.Values) == 0 {
+		return nil, fmt.Errorf("no values provided for set generator")
+	}
+
 	if len(g.Weights) > 0 {
 		items, err := g.buildWeightedItems()
 		if err != nil {
-			return fmt.Errorf("making weighted items collection: %w", err)
-		}
-
-		for i := 0; i < count; i++ {
-			line = append(line, items.choose())
-		}
-	} else {
-		for i := 0; i < count; i++ {
-			line = append(line, g.Values[random.Intn(len(g.Values))])
+			return nil, fmt.Errorf("making weighted items collection: %w", err)
 		}
+		return func() string {
+			return items.choose(r)
+		}, nil
 	}
 
-	AddTable(t, c.Name, line, files)
-	return nil
+	return func() string {
+		return g.Values[r.IntN(len(g.Values))]
+	}, nil
 }
 
 func (g SetGenerator) buildWeightedItems() (weightedItems, error) {
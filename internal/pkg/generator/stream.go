@@ -0,0 +1,162 @@
+package generator
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/samber/lo"
+)
+
+// valueFunc returns the next value of a column each time it's called.
+type valueFunc func() string
+
+// streamTypes are the processors that generate each value independently of
+// the rest of the table, so can generate a table a chunk at a time.
+var streamTypes = []string{"gen", "set", "inc", "rand", "cuid2", "ref"}
+
+// Streamable returns true if a table's rows can be generated a chunk at a
+// time. Streamable tables have a count, no unique columns, no where
+// expression and only columns whose processors generate each value
+// independently of the rest of the table.
+func Streamable(t model.Table) (bool, error) {
+	if t.Count <= 0 || len(t.UniqueColumns) > 0 || t.Where != "" {
+		return false, nil
+	}
+
+	for _, c := range t.Columns {
+		if !lo.Contains(streamTypes, c.Type) || c.Generator.UnmarshalFunc == nil {
+			return false, nil
+		}
+
+		ref, ok, err := ColumnReference(c)
+		if err != nil {
+			return false, err
+		}
+		if ok && ref.Table == t.Name {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// Stream generates the rows of a streamable table a chunk at a time. The
+// values it generates are the same as those generated for the whole table
+// at once.
+type Stream struct {
+	table     model.Table
+	sequences []*sequence
+	rows      int
+}
+
+// NewStream returns a stream of a table's rows, reading any referenced
+// tables from files.
+func NewStream(t model.Table, files map[string]model.CSVFile) (*Stream, error) {
+	s := Stream{table: t}
+
+	for _, c := range t.Columns {
+		values, err := columnBlocks(c, files)
+		if err != nil {
+			return nil, c.Position.Wrap(fmt.Errorf("running %s process for %s.%s: %w", c.Type, t.Name, c.Name, err))
+		}
+
+		seq, err := newSequence(t, c, values)
+		if err != nil {
+			return nil, c.Position.Wrap(fmt.Errorf("running %s process for %s.%s: %w", c.Type, t.Name, c.Name, err))
+		}
+		s.sequences = append(s.sequences, seq)
+	}
+
+	return &s, nil
+}
+
+// Header returns the names of the stream's columns.
+func (s *Stream) Header() []string {
+	return lo.Map(s.table.Columns, func(c model.Column, _ int) string {
+		return c.Name
+	})
+}
+
+// Next returns up to n rows as columns, in the order of the stream's header.
+// It returns no columns once all of the table's rows have been generated.
+// Columns are generated across workers.
+func (s *Stream) Next(n int) ([][]string, error) {
+	n = min(n, s.table.Count-s.rows)
+	if n <= 0 {
+		return nil, nil
+	}
+	s.rows += n
+
+	lines := make([][]string, len(s.sequences))
+	errs := make([]error, len(s.sequences))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+	for i, seq := range s.sequences {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, seq *sequence) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			lines[i], errs[i] = seq.values(n)
+		}(i, seq)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			c := s.table.Columns[i]
+			return nil, c.Position.Wrap(fmt.Errorf("running %s process for %s.%s: %w", c.Type, s.table.Name, c.Name, err))
+		}
+	}
+	return lines, nil
+}
+
+func columnBlocks(c model.Column, files map[string]model.CSVFile) (blockFunc, error) {
+	switch c.Type {
+	case "gen":
+		var g GenGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return nil, err
+		}
+		return g.values, nil
+
+	case "set":
+		var g SetGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return nil, err
+		}
+		return g.values, nil
+
+	case "inc":
+		var g IncGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return nil, err
+		}
+		return g.values, nil
+
+	case "rand":
+		var g RandGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return nil, err
+		}
+		return g.values, nil
+
+	case "cuid2":
+		var g Cuid2Generator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return nil, err
+		}
+		return g.values, nil
+
+	case "ref":
+		var g RefGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return nil, err
+		}
+		return g.values(files)
+	}
+
+	return nil, fmt.Errorf("%s columns can't be streamed", c.Type)
+}
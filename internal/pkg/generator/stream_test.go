@@ -0,0 +1,139 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/codingconcepts/dg/internal/pkg/random"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamable(t *testing.T) {
+	column := func(typ string, processor map[string]any) model.Column {
+		return model.Column{Name: "col", Type: typ, Generator: model.ToRawMessage(t, processor)}
+	}
+
+	cases := []struct {
+		name  string
+		table model.Table
+		exp   bool
+	}{
+		{
+			name: "streamable",
+			table: model.Table{Name: "person", Count: 10, Columns: []model.Column{
+				column("inc", map[string]any{"start": 1}),
+				column("gen", map[string]any{"value": "${name}"}),
+				column("ref", map[string]any{"table": "market", "column": "code"}),
+			}},
+			exp: true,
+		},
+		{
+			name: "no count",
+			table: model.Table{Name: "person", Columns: []model.Column{
+				column("inc", map[string]any{"start": 1}),
+			}},
+		},
+		{
+			name: "unique columns",
+			table: model.Table{Name: "person", Count: 10, UniqueColumns: []string{"col"}, Columns: []model.Column{
+				column("gen", map[string]any{"value": "${name}"}),
+			}},
+		},
+		{
+			name: "where expression",
+			table: model.Table{Name: "person", Count: 10, Where: "col != ''", Columns: []model.Column{
+				column("gen", map[string]any{"value": "${name}"}),
+			}},
+		},
+		{
+			name: "other type",
+			table: model.Table{Name: "person", Count: 10, Columns: []model.Column{
+				column("expr", map[string]any{"expression": "1"}),
+			}},
+		},
+		{
+			name: "references own table",
+			table: model.Table{Name: "person", Count: 10, Columns: []model.Column{
+				column("ref", map[string]any{"table": "person", "column": "id"}),
+			}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			act, err := Streamable(c.table)
+			assert.NoError(t, err)
+			assert.Equal(t, c.exp, act)
+		})
+	}
+}
+
+func TestStream(t *testing.T) {
+	table := model.Table{
+		Name:  "person",
+		Count: blockRows*2 + 5,
+		Columns: []model.Column{
+			{Name: "id", Type: "inc", Generator: model.ToRawMessage(t, map[string]any{"start": 1})},
+			{Name: "name", Type: "gen", Generator: model.ToRawMessage(t, map[string]any{"value": "${name}"})},
+			{Name: "age", Type: "rand", Generator: model.ToRawMessage(t, map[string]any{"type": "int", "low": 18, "high": 80})},
+			{Name: "market", Type: "ref", Generator: model.ToRawMessage(t, map[string]any{"table": "market", "column": "code"})},
+			{Name: "status", Type: "set", Generator: model.ToRawMessage(t, map[string]any{"values": []string{"a", "b"}})},
+		},
+	}
+
+	market := model.CSVFile{
+		Name:   "market",
+		Header: []string{"code"},
+		Lines:  [][]string{{"us", "in", "de"}},
+	}
+
+	random.Seed(42)
+	files := map[string]model.CSVFile{"market": market}
+	s, err := NewStream(table, files)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"id", "name", "age", "market", "status"}, s.Header())
+
+	streamed := make([][]string, len(table.Columns))
+	for {
+		lines, err := s.Next(7000)
+		assert.NoError(t, err)
+		if lines == nil {
+			break
+		}
+		for i := range lines {
+			streamed[i] = append(streamed[i], lines[i]...)
+		}
+	}
+	assert.Len(t, streamed[0], table.Count)
+
+	random.Seed(42)
+	files = map[string]model.CSVFile{"market": market}
+	for _, c := range table.Columns {
+		var err error
+		switch c.Type {
+		case "inc":
+			var g IncGenerator
+			assert.NoError(t, c.Generator.UnmarshalFunc(&g))
+			err = g.Generate(table, c, files)
+		case "gen":
+			var g GenGenerator
+			assert.NoError(t, c.Generator.UnmarshalFunc(&g))
+			err = g.Generate(table, c, files)
+		case "rand":
+			var g RandGenerator
+			assert.NoError(t, c.Generator.UnmarshalFunc(&g))
+			err = g.Generate(table, c, files)
+		case "ref":
+			var g RefGenerator
+			assert.NoError(t, c.Generator.UnmarshalFunc(&g))
+			err = g.Generate(table, c, files)
+		case "set":
+			var g SetGenerator
+			assert.NoError(t, c.Generator.UnmarshalFunc(&g))
+			err = g.Generate(table, c, files)
+		}
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, files["person"].Lines, streamed)
+}
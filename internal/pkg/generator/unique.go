@@ -0,0 +1,251 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/codingconcepts/dg/internal/pkg/random"
+	"github.com/samber/lo"
+)
+
+// retryTypes are the processors whose values can be regenerated for a row
+// that duplicates the unique columns of an earlier row.
+var retryTypes = []string{"gen", "rand", "set", "expr"}
+
+// defaultUniqueRetries is the number of times a row is regenerated before
+// giving up, if a table doesn't set unique_retries.
+const defaultUniqueRetries = 100
+
+// RetryUnique makes the unique columns of a table's rows unique by
+// regenerating rows that duplicate an earlier row, rather than dropping
+// them, so that the table keeps its count. The unique columns that can be
+// regenerated are regenerated for each duplicate row, along with any
+// columns that read from them.
+func RetryUnique(t model.Table, files map[string]model.CSVFile) error {
+	file, ok := files[t.Name]
+	if !ok {
+		return fmt.Errorf("missing table: %q", t.Name)
+	}
+
+	uniqueIndexes := make([]int, len(t.UniqueColumns))
+	for i, name := range t.UniqueColumns {
+		if uniqueIndexes[i] = lo.IndexOf(file.Header, name); uniqueIndexes[i] == -1 {
+			return fmt.Errorf("missing unique column %q", name)
+		}
+	}
+
+	rows := 0
+	if len(file.Lines) > 0 {
+		rows = len(file.Lines[0])
+	}
+	if err := checkUniqueCapacity(t, rows); err != nil {
+		return err
+	}
+
+	columns, err := retryColumns(t)
+	if err != nil {
+		return err
+	}
+
+	regenerators := make([]func(row int) (string, error), len(columns))
+	columnIndexes := make([]int, len(columns))
+	for i, c := range columns {
+		if regenerators[i], err = regenerator(t, c, files); err != nil {
+			return c.Position.Wrap(fmt.Errorf("preparing to regenerate %s.%s: %w", t.Name, c.Name, err))
+		}
+
+		// Copy the columns being regenerated, so that any indexes built from
+		// them are rebuilt.
+		columnIndexes[i] = lo.IndexOf(file.Header, c.Name)
+		file.Lines[columnIndexes[i]] = slices.Clone(file.Lines[columnIndexes[i]])
+	}
+	files[t.Name] = file
+
+	retries := t.UniqueRetries
+	if retries <= 0 {
+		retries = defaultUniqueRetries
+	}
+
+	seen := model.NewRowSet(file.Lines, uniqueIndexes)
+	for row := 0; row < rows; row++ {
+		for attempt := 0; !seen.Add(row); attempt++ {
+			if attempt == retries {
+				return fmt.Errorf("couldn't make row %d of %q unique after %d retries; the unique columns (%s) may not have enough distinct values for %d rows", row+1, t.Name, retries, strings.Join(t.UniqueColumns, ", "), rows)
+			}
+
+			for i, regenerate := range regenerators {
+				value, err := regenerate(row)
+				if err != nil {
+					return columns[i].Position.Wrap(fmt.Errorf("regenerating %s.%s: %w", t.Name, columns[i].Name, err))
+				}
+				file.Lines[columnIndexes[i]][row] = value
+			}
+		}
+	}
+
+	return nil
+}
+
+// retryColumns returns the columns of a table to regenerate for a duplicate
+// row, in the order they're generated: the unique columns that can be
+// regenerated and the columns that read from them.
+func retryColumns(t model.Table) ([]model.Column, error) {
+	sorted, err := SortColumns(t)
+	if err != nil {
+		return nil, fmt.Errorf("ordering columns: %w", err)
+	}
+
+	retry := map[string]bool{}
+	for _, c := range sorted {
+		if lo.Contains(t.UniqueColumns, c.Name) && lo.Contains(retryTypes, c.Type) {
+			retry[c.Name] = true
+		}
+	}
+	if len(retry) == 0 {
+		return nil, fmt.Errorf("none of the unique columns of %q can be regenerated, as they're not %s columns", t.Name, strings.Join(retryTypes, ", "))
+	}
+
+	var columns []model.Column
+	for _, c := range sorted {
+		if !retry[c.Name] {
+			deps, err := ColumnDependencies(t, c)
+			if err != nil {
+				return nil, err
+			}
+
+			read, ok := lo.Find(deps, func(d string) bool { return retry[d] })
+			if !ok {
+				continue
+			}
+			if !lo.Contains(retryTypes, c.Type) {
+				return nil, fmt.Errorf("%s.%s reads from %s, which may be regenerated, but %s columns can't be regenerated", t.Name, c.Name, read, c.Type)
+			}
+			retry[c.Name] = true
+		}
+		columns = append(columns, c)
+	}
+
+	return columns, nil
+}
+
+// regenerator returns a function that generates a new value of a column for
+// the given row. New values are drawn from a random source of their own, so
+// that regenerating rows doesn't change the values of any other rows.
+func regenerator(t model.Table, c model.Column, files map[string]model.CSVFile) (func(row int) (string, error), error) {
+	r := random.Derive(t.Name, c.Name, "retry")
+
+	var values blockFunc
+	switch c.Type {
+	case "gen":
+		var g GenGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return nil, err
+		}
+		values = g.values
+
+	case "rand":
+		var g RandGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return nil, err
+		}
+		values = g.values
+
+	case "set":
+		var g SetGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return nil, err
+		}
+		values = g.values
+
+	case "expr":
+		var g ExprGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return nil, err
+		}
+		ec := &ExprContext{Files: files, Format: g.Format, Rand: r}
+		return func(row int) (string, error) {
+			lines, err := g.evaluateRows(ec, t, files, row, 1, 1)
+			if err != nil || len(lines) == 0 {
+				return "", err
+			}
+			return lines[0], nil
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("%s columns can't be regenerated", c.Type)
+	}
+
+	next, err := values(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	return func(int) (string, error) {
+		return next(), nil
+	}, nil
+}
+
+// checkUniqueCapacity returns an error if the unique columns of a table
+// can't have enough distinct combinations for the given number of rows.
+// Only set and int rand columns have a known number of distinct values, so
+// tables with any other unique columns pass.
+func checkUniqueCapacity(t model.Table, rows int) error {
+	combinations := 1
+	for _, name := range t.UniqueColumns {
+		c, ok := lo.Find(t.Columns, func(c model.Column) bool { return c.Name == name })
+		if !ok {
+			return nil
+		}
+
+		n := distinctValues(c)
+		if n < 0 {
+			return nil
+		}
+		if n > 0 && combinations > math.MaxInt/n {
+			return nil
+		}
+		combinations *= n
+	}
+
+	if combinations < rows {
+		return fmt.Errorf("%q can't have %d unique rows, as its unique columns (%s) only have %d distinct combinations", t.Name, rows, strings.Join(t.UniqueColumns, ", "), combinations)
+	}
+	return nil
+}
+
+// distinctValues returns the number of distinct values a column can have,
+// or -1 if it isn't known.
+func distinctValues(c model.Column) int {
+	if c.Generator.UnmarshalFunc == nil {
+		return -1
+	}
+
+	switch c.Type {
+	case "set":
+		var g SetGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return -1
+		}
+		return len(lo.Uniq(g.Values))
+
+	case "rand":
+		var g RandGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil || g.Type != "int" {
+			return -1
+		}
+		low, lowErr := strconv.Atoi(g.Low)
+		high, highErr := strconv.Atoi(g.High)
+		if lowErr != nil || highErr != nil {
+			return -1
+		}
+		if low > high {
+			low, high = high, low
+		}
+		return high - low
+	}
+
+	return -1
+}
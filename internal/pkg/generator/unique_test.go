@@ -0,0 +1,144 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryUnique(t *testing.T) {
+	column := func(name, typ string, processor map[string]any) model.Column {
+		return model.Column{Name: name, Type: typ, Generator: model.ToRawMessage(t, processor)}
+	}
+
+	cases := []struct {
+		name   string
+		table  model.Table
+		lines  [][]string
+		expErr string
+	}{
+		{
+			name: "set",
+			table: model.Table{
+				Name:          "person",
+				UniqueColumns: []string{"colour"},
+				UniqueRetries: 1000,
+				Columns: []model.Column{
+					column("colour", "set", map[string]any{"values": []string{"red", "green", "blue"}}),
+				},
+			},
+			lines: [][]string{{"red", "red", "red"}},
+		},
+		{
+			name: "composite key with dependent column",
+			table: model.Table{
+				Name:          "person",
+				UniqueColumns: []string{"id", "age"},
+				Columns: []model.Column{
+					column("id", "inc", map[string]any{"start": 1}),
+					column("age", "rand", map[string]any{"type": "int", "low": 1, "high": 100}),
+					column("label", "expr", map[string]any{"expression": "id + '-' + age"}),
+				},
+			},
+			lines: [][]string{
+				{"1", "1", "1", "2"},
+				{"5", "5", "5", "5"},
+				{"1-5", "1-5", "1-5", "2-5"},
+			},
+		},
+		{
+			name: "not enough values",
+			table: model.Table{
+				Name:          "person",
+				UniqueColumns: []string{"colour"},
+				Columns: []model.Column{
+					column("colour", "set", map[string]any{"values": []string{"red", "green"}}),
+				},
+			},
+			lines:  [][]string{{"red", "red", "red"}},
+			expErr: `"person" can't have 3 unique rows, as its unique columns (colour) only have 2 distinct combinations`,
+		},
+		{
+			name: "retries exhausted",
+			table: model.Table{
+				Name:          "person",
+				UniqueColumns: []string{"name"},
+				UniqueRetries: 3,
+				Columns: []model.Column{
+					column("name", "gen", map[string]any{"value": "a"}),
+				},
+			},
+			lines:  [][]string{{"a", "a"}},
+			expErr: `couldn't make row 2 of "person" unique after 3 retries; the unique columns (name) may not have enough distinct values for 2 rows`,
+		},
+		{
+			name: "no columns to regenerate",
+			table: model.Table{
+				Name:          "person",
+				UniqueColumns: []string{"id"},
+				Columns: []model.Column{
+					column("id", "inc", map[string]any{"start": 1}),
+				},
+			},
+			lines:  [][]string{{"1", "1"}},
+			expErr: `none of the unique columns of "person" can be regenerated, as they're not gen, rand, set, expr columns`,
+		},
+		{
+			name: "dependent column can't be regenerated",
+			table: model.Table{
+				Name:          "person",
+				UniqueColumns: []string{"code"},
+				Columns: []model.Column{
+					column("code", "gen", map[string]any{"value": "${uuid}"}),
+					column("name", "match", map[string]any{"source_table": "names", "source_column": "code", "source_value": "name", "match_column": "code"}),
+				},
+			},
+			lines:  [][]string{{"a", "a"}, {"x", "x"}},
+			expErr: "person.name reads from code, which may be regenerated, but match columns can't be regenerated",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			files := map[string]model.CSVFile{
+				"person": {
+					Name: "person",
+					Header: lo.Map(c.table.Columns, func(c model.Column, _ int) string {
+						return c.Name
+					}),
+					Lines: c.lines,
+				},
+			}
+
+			err := RetryUnique(c.table, files)
+			if c.expErr != "" {
+				assert.EqualError(t, err, c.expErr)
+				return
+			}
+			assert.NoError(t, err)
+
+			file := files["person"]
+			indexes := lo.Map(c.table.UniqueColumns, func(name string, _ int) int {
+				return lo.IndexOf(file.Header, name)
+			})
+			seen := model.NewRowSet(file.Lines, indexes)
+			for row := range file.Lines[0] {
+				assert.True(t, seen.Add(row), "row %d isn't unique", row+1)
+			}
+
+			// Rows that weren't duplicates are untouched.
+			for i := range c.lines {
+				assert.Equal(t, c.lines[i][0], file.Lines[i][0])
+			}
+
+			// Columns that read from regenerated columns are kept consistent.
+			if i := lo.IndexOf(file.Header, "label"); i != -1 {
+				for row := range file.Lines[i] {
+					assert.Equal(t, file.Lines[0][row]+"-"+file.Lines[1][row], file.Lines[i][row])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,344 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+
+	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/expr-lang/expr"
+	"github.com/samber/lo"
+)
+
+// processors returns an empty processor for each type of column, for its
+// configuration to be decoded into.
+var processors = map[string]func() any{
+	"case":          func() any { return &CaseGenerator{} },
+	"const":         func() any { return &ConstGenerator{} },
+	"cuid2":         func() any { return &Cuid2Generator{} },
+	"dist":          func() any { return &DistGenerator{} },
+	"each":          func() any { return &EachGenerator{} },
+	"expr":          func() any { return &ExprGenerator{} },
+	"fk":            func() any { return &ForeignKeyGenerator{} },
+	"gen":           func() any { return &GenGenerator{} },
+	"inc":           func() any { return &IncGenerator{} },
+	"lookup":        func() any { return &LookupGenerator{} },
+	"map":           func() any { return &MapGenerator{} },
+	"match":         func() any { return &MatchGenerator{} },
+	"pick":          func() any { return &PickGenerator{} },
+	"rand":          func() any { return &RandGenerator{} },
+	"range":         func() any { return &RangeGenerator{} },
+	"ref":           func() any { return &RefGenerator{} },
+	"rel_date":      func() any { return &RelDateGenerator{} },
+	"relative_date": func() any { return &RelDateGenerator{} },
+	"set":           func() any { return &SetGenerator{} },
+}
+
+// KnownType returns true if a column type has a processor.
+func KnownType(columnType string) bool {
+	_, ok := processors[columnType]
+	return ok
+}
+
+// Validate checks a config without generating any data. It decodes each
+// processor, reporting fields that the processor doesn't have, checks that
+// the tables and columns that processors refer to exist, and type checks
+// expressions against the fields of the records they're evaluated with.
+// files holds the config's inputs, whose columns aren't checked if they have
+// no header (e.g. if they couldn't be loaded). Every problem found is returned, as
+// model.ConfigErrors joined with errors.Join.
+func Validate(c model.Config, files map[string]model.CSVFile) error {
+	v := validator{tables: map[string][]string{}}
+	for name, file := range files {
+		v.tables[name] = file.Header
+	}
+
+	for _, input := range c.Inputs {
+		v.input(input)
+	}
+
+	for _, t := range c.Tables {
+		if t.Name == "" {
+			v.errorf(t.Position, "table has no name")
+			continue
+		}
+		if _, ok := v.tables[t.Name]; ok {
+			v.errorf(t.Position, "table %q is declared more than once", t.Name)
+		}
+		v.tables[t.Name] = lo.Map(t.Columns, func(c model.Column, _ int) string {
+			return c.Name
+		})
+	}
+
+	for _, t := range c.Tables {
+		v.table(t)
+	}
+
+	// Ordering decodes every processor, so it's only checked for configs
+	// whose processors are valid.
+	if len(v.errs) == 0 {
+		if _, err := SortTables(c.Tables); err != nil {
+			v.errs = append(v.errs, model.Position{File: c.File}.Wrap(err))
+		}
+		for _, t := range c.Tables {
+			if _, err := SortColumns(t); err != nil {
+				v.errorf(t.Position, "%s: %v", t.Name, err)
+			}
+		}
+	}
+
+	return errors.Join(v.errs...)
+}
+
+type validator struct {
+	// tables holds the columns of each table and input.
+	tables map[string][]string
+
+	// functions holds the functions available to expressions.
+	functions map[string]any
+
+	errs []error
+}
+
+func (v *validator) errorf(pos model.Position, format string, args ...any) {
+	v.errs = append(v.errs, model.ConfigError{Position: pos, Err: fmt.Errorf(format, args...)})
+}
+
+func (v *validator) input(input model.Input) {
+	switch input.Type {
+	case "csv":
+		v.decode(input.Source, input.Name, &model.SourceCSV{})
+	default:
+		v.errorf(input.Position, "%s: unknown input type %q", input.Name, input.Type)
+	}
+}
+
+// decode decodes a processor or source, reporting any problems with it under
+// the given name.
+func (v *validator) decode(msg model.RawMessage, name string, out any) bool {
+	err := msg.DecodeStrict(out)
+	for _, err := range model.Errors(err) {
+		var configErr model.ConfigError
+		if errors.As(err, &configErr) {
+			configErr.Err = fmt.Errorf("%s: %w", name, configErr.Err)
+			err = configErr
+		}
+		v.errs = append(v.errs, err)
+	}
+	return err == nil
+}
+
+func (v *validator) table(t model.Table) {
+	if t.Count < 0 {
+		v.errorf(t.Position, "%s: count can't be negative", t.Name)
+	}
+	if r := t.CountRange; r != nil && (r.Min < 0 || r.Min > r.Max) {
+		v.errorf(t.Position, "%s: invalid count range (min %d, max %d)", t.Name, r.Min, r.Max)
+	}
+	v.expressions(t.Position, t, model.Column{Name: "count"}, nil, t.CountExpression)
+	v.expressions(t.Position, t, model.Column{Name: "where"}, v.record(t.Name), t.Where)
+
+	switch t.UniqueMode {
+	case "", model.UniqueDrop, model.UniqueRetry:
+	default:
+		v.errorf(t.Position, "%s: invalid unique_mode %q (expected %s or %s)", t.Name, t.UniqueMode, model.UniqueDrop, model.UniqueRetry)
+	}
+
+	for _, name := range t.UniqueColumns {
+		if !lo.ContainsBy(t.Columns, func(c model.Column) bool { return c.Name == name }) {
+			v.errorf(t.Position, "%s: unique column %q doesn't exist", t.Name, name)
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, c := range t.Columns {
+		if c.Name == "" {
+			v.errorf(c.Position, "%s: column has no name", t.Name)
+			continue
+		}
+		if seen[c.Name] {
+			v.errorf(c.Position, "%s.%s: column is declared more than once", t.Name, c.Name)
+		}
+		seen[c.Name] = true
+
+		v.column(t, c)
+	}
+}
+
+func (v *validator) column(t model.Table, c model.Column) {
+	if c.DataType != "" && !c.DataType.Valid() {
+		v.errorf(c.Position, "%s.%s: invalid data_type %q", t.Name, c.Name, c.DataType)
+	}
+
+	newProcessor, ok := processors[c.Type]
+	if !ok {
+		v.errorf(c.Position, "%s.%s: unknown type %q", t.Name, c.Name, c.Type)
+		return
+	}
+	if c.Generator.UnmarshalFunc == nil {
+		v.errorf(c.Position, "%s.%s: missing processor", t.Name, c.Name)
+		return
+	}
+
+	pos := c.Generator.Position
+	if pos.Line == 0 {
+		pos = c.Position
+	}
+
+	processor := newProcessor()
+	if !v.decode(c.Generator, t.Name+"."+c.Name, processor) {
+		return
+	}
+
+	record := v.record(t.Name)
+
+	switch g := processor.(type) {
+	case *RefGenerator:
+		v.reference(pos, t, c, g.Table, g.Column)
+
+	case *EachGenerator:
+		v.reference(pos, t, c, g.Table, g.Column)
+		switch g.Sample {
+		case "", SampleSequential, SampleRandom:
+		default:
+			v.errorf(pos, "%s.%s: invalid sample %q (expected %s or %s)", t.Name, c.Name, g.Sample, SampleSequential, SampleRandom)
+		}
+
+	case *ForeignKeyGenerator:
+		v.reference(pos, t, c, g.Table, g.Column)
+
+		fields := maps.Clone(record)
+		fields[lo.CoalesceOrEmpty(g.ReferenceAs, "parent")] = map[string]any{}
+		fields[lo.CoalesceOrEmpty(g.SkippedAs, "skipped")] = 0
+		v.expressions(pos, t, c, fields, g.Filter, g.Repeat)
+
+	case *MatchGenerator:
+		v.reference(pos, t, c, g.SourceTable, g.SourceColumn, g.SourceValue)
+		v.reference(pos, t, c, t.Name, g.MatchColumn)
+
+	case *PickGenerator:
+		v.reference(pos, t, c, g.SourceTable, g.SourceColumn, g.SourceValue)
+		v.reference(pos, t, c, t.Name, g.MatchColumn)
+
+	case *LookupGenerator:
+		v.reference(pos, t, c, t.Name, g.MatchColumn)
+
+		// The repeat expression is evaluated with the record of the last
+		// lookup table with an expression.
+		var fields map[string]any
+		for _, lt := range g.LookupTables {
+			v.reference(pos, t, c, lt.SourceTable, lo.Compact([]string{lt.SourceColumn, lt.SourceValue})...)
+			if lt.SourceValue == "" && lt.Expression == "" {
+				v.errorf(pos, "%s.%s: lookup of %q needs a source_value or an expression", t.Name, c.Name, lt.SourceTable)
+			}
+
+			lookupFields := v.record(lt.SourceTable)
+			v.expressions(pos, t, c, lookupFields, lt.Expression, lt.Predicate)
+			if lt.Expression != "" {
+				fields = lookupFields
+			}
+		}
+		v.expressions(pos, t, c, fields, g.Repeat)
+
+	case *MapGenerator:
+		table := lo.CoalesceOrEmpty(g.Table, t.Name)
+		v.reference(pos, t, c, table, g.Column)
+
+		fields := maps.Clone(record)
+		fields[model.ROW_VALUE] = ""
+		fields[model.VALUE_INDEX] = 0
+		fields[model.VALUE_COUNT] = 0
+		v.expressions(pos, t, c, fields, g.Expression)
+
+	case *RangeGenerator:
+		if g.Table != "" {
+			v.referenceTable(pos, t, c, g.Table)
+		}
+
+	case *DistGenerator:
+		v.expressions(pos, t, c, nil, g.Expression)
+
+	case *ExprGenerator, *CaseGenerator, *RelDateGenerator:
+		expressions, err := columnExpressions(c)
+		if err != nil {
+			v.errorf(pos, "%s.%s: %v", t.Name, c.Name, err)
+			return
+		}
+		v.expressions(pos, t, c, record, expressions...)
+	}
+}
+
+// referenceTable reports a table that a column refers to if it doesn't
+// exist, returning false.
+func (v *validator) referenceTable(pos model.Position, t model.Table, c model.Column, table string) bool {
+	if table == "" {
+		v.errorf(pos, "%s.%s: missing table", t.Name, c.Name)
+		return false
+	}
+	if _, ok := v.tables[table]; !ok {
+		v.errorf(pos, "%s.%s: table %q doesn't exist", t.Name, c.Name, table)
+		return false
+	}
+	return true
+}
+
+// reference reports the table that a column refers to if it doesn't exist,
+// or else any of the table's columns that it refers to that don't exist.
+func (v *validator) reference(pos model.Position, t model.Table, c model.Column, table string, columns ...string) {
+	if !v.referenceTable(pos, t, c, table) {
+		return
+	}
+	for _, column := range columns {
+		if column == "" {
+			v.errorf(pos, "%s.%s: missing column of %q", t.Name, c.Name, table)
+		} else if cols := v.tables[table]; cols != nil && !lo.Contains(cols, column) {
+			v.errorf(pos, "%s.%s: column %q doesn't exist in %q", t.Name, c.Name, column, table)
+		}
+	}
+}
+
+// record returns the fields of the records of a table that expressions are
+// evaluated with. Values are read from files as strings.
+func (v *validator) record(table string) map[string]any {
+	fields := map[string]any{
+		model.ROW_NUMBER:   0,
+		model.ROWS_SKIPPED: 0,
+	}
+	for _, column := range v.tables[table] {
+		fields[column] = ""
+	}
+	return fields
+}
+
+// expressions compiles a column's expressions against the functions
+// available to expressions and the given fields, and checks that the tables
+// they name exist.
+func (v *validator) expressions(pos model.Position, t model.Table, c model.Column, fields map[string]any, expressions ...string) {
+	if v.functions == nil {
+		v.functions = (&ExprContext{}).functions()
+	}
+
+	env := maps.Clone(v.functions)
+	for name, value := range fields {
+		if _, ok := env[name]; !ok {
+			env[name] = value
+		}
+	}
+
+	for _, e := range lo.Compact(expressions) {
+		names, err := inspectExpression(e)
+		if err != nil {
+			v.errorf(pos, "%s.%s: parsing expression %q: %v", t.Name, c.Name, e, err)
+			continue
+		}
+		for _, table := range names.tables {
+			if _, ok := v.tables[table]; !ok {
+				v.errorf(pos, "%s.%s: expression %q reads table %q, which doesn't exist", t.Name, c.Name, e, table)
+			}
+		}
+
+		if _, err := expr.Compile(e, expr.Env(env)); err != nil {
+			v.errorf(pos, "%s.%s: checking expression: %v", t.Name, c.Name, err)
+		}
+	}
+}
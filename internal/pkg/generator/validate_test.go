@@ -0,0 +1,311 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		config  string
+		files   map[string]model.CSVFile
+		expErrs []string
+	}{
+		{
+			name: "valid",
+			config: `
+tables:
+  - name: person
+    count: 10
+    unique_columns: [id]
+    columns:
+      - name: id
+        type: inc
+        processor:
+          start: 1
+      - name: label
+        type: expr
+        processor:
+          expression: string(int(id) * 2) + market
+      - name: market
+        type: ref
+        processor:
+          table: market
+          column: code`,
+			files: map[string]model.CSVFile{
+				"market": {Name: "market", Header: []string{"code"}},
+			},
+		},
+		{
+			name: "inputs",
+			config: `
+inputs:
+  - name: market
+    type: csv
+    source:
+      file: market.csv
+  - name: region
+    type: json
+    source:
+      file_name: region.json`,
+			expErrs: []string{
+				`6:7: market: unknown field "file"`,
+				`7:5: region: unknown input type "json"`,
+			},
+		},
+		{
+			name: "unknown fields",
+			config: `
+tables:
+  - name: person
+    count: 10
+    columns:
+      - name: id
+        type: inc
+        processor:
+          strat: 1
+          format: "%d"`,
+			expErrs: []string{
+				`9:11: person.id: unknown field "strat"`,
+			},
+		},
+		{
+			name: "values that can't be decoded",
+			config: `
+tables:
+  - name: person
+    columns:
+      - name: age
+        type: rand
+        processor:
+          type: int
+          low: [1]`,
+			expErrs: []string{
+				`9: person.age: cannot unmarshal !!seq into string`,
+			},
+		},
+		{
+			name: "unknown and missing types",
+			config: `
+tables:
+  - name: person
+    columns:
+      - name: id
+        type: incc
+      - name: name
+        type: gen`,
+			expErrs: []string{
+				`5:9: person.id: unknown type "incc"`,
+				`7:9: person.name: missing processor`,
+			},
+		},
+		{
+			name: "missing references",
+			config: `
+tables:
+  - name: person
+    columns:
+      - name: id
+        type: inc
+        processor:
+          start: 1
+  - name: pet
+    columns:
+      - name: person_id
+        type: ref
+        processor:
+          table: person
+          column: person_id
+      - name: owner
+        type: match
+        processor:
+          source_table: owner
+          source_column: id
+          source_value: name
+          match_column: person_id
+      - name: kind
+        type: pick
+        processor:
+          source_table: person
+          source_column: id
+          source_value: id
+          match_column: breed`,
+			expErrs: []string{
+				`14:11: pet.person_id: column "person_id" doesn't exist in "person"`,
+				`19:11: pet.owner: table "owner" doesn't exist`,
+				`26:11: pet.kind: column "breed" doesn't exist in "pet"`,
+			},
+		},
+		{
+			name: "expressions",
+			config: `
+tables:
+  - name: person
+    columns:
+      - name: id
+        type: inc
+        processor:
+          start: 1
+      - name: double
+        type: expr
+        processor:
+          expression: id * 2
+      - name: typo
+        type: expr
+        processor:
+          expression: upper(nmae)
+      - name: other
+        type: expr
+        processor:
+          expression: get_column("nowhere", "id")[0]`,
+			expErrs: []string{
+				`12:11: person.double: checking expression: invalid operation: * (mismatched types string and int) (1:4)`,
+				`16:11: person.typo: checking expression: unknown name nmae (1:7)`,
+				`20:11: person.other: expression "get_column(\"nowhere\", \"id\")[0]" reads table "nowhere", which doesn't exist`,
+			},
+		},
+		{
+			name: "expression fields of other processors",
+			config: `
+tables:
+  - name: person
+    columns:
+      - name: id
+        type: inc
+        processor:
+          start: 1
+  - name: pet
+    columns:
+      - name: person_id
+        type: fk
+        processor:
+          table: person
+          column: id
+          reference_as: owner
+          filter: skipped < 2 && owner.id != ""
+      - name: pets
+        type: map
+        processor:
+          column: person_id
+          expression: value + string(index) + string(count)`,
+		},
+		{
+			name: "tables",
+			config: `
+tables:
+  - name: person
+    count: -1
+    unique_mode: keep
+    unique_columns: [email]
+    columns:
+      - name: id
+        type: inc
+        processor:
+          start: 1
+      - name: id
+        type: inc
+        processor:
+          start: 1
+  - name: person
+    columns:
+      - name: id
+        type: inc
+        processor:
+          start: 1`,
+			expErrs: []string{
+				`16:5: table "person" is declared more than once`,
+				`3:5: person: count can't be negative`,
+				`3:5: person: invalid unique_mode "keep" (expected drop or retry)`,
+				`3:5: person: unique column "email" doesn't exist`,
+				`12:9: person.id: column is declared more than once`,
+			},
+		},
+		{
+			name: "counts",
+			config: `
+tables:
+  - name: person
+    count: {min: 10, max: 20}
+    columns:
+      - name: id
+        type: inc
+        processor:
+          start: 1
+  - name: pet
+    count: len(get_column("person", "id")) * 2
+    columns:
+      - name: id
+        type: inc
+        processor:
+          start: 1
+  - name: toy
+    count: {min: 5, max: 1}
+    columns:
+      - name: id
+        type: inc
+        processor:
+          start: 1
+  - name: shop
+    count: len(get_column("owner", "id")) + pets
+    columns:
+      - name: id
+        type: inc
+        processor:
+          start: 1`,
+			expErrs: []string{
+				`17:5: toy: invalid count range (min 5, max 1)`,
+				`24:5: shop.count: expression "len(get_column(\"owner\", \"id\")) + pets" reads table "owner", which doesn't exist`,
+				`24:5: shop.count: checking expression: unknown name pets (1:34)`,
+			},
+		},
+		{
+			name: "where expressions",
+			config: `
+tables:
+  - name: order
+    where: date(placed) < now() && row_number > 0
+    columns:
+      - name: placed
+        type: gen
+        processor:
+          value: ${date}
+  - name: item
+    where: order_id in get_column("orders", "id") && qty > 0
+    columns:
+      - name: order_id
+        type: gen
+        processor:
+          value: ${uuid}`,
+			expErrs: []string{
+				`10:5: item.where: expression "order_id in get_column(\"orders\", \"id\") && qty > 0" reads table "orders", which doesn't exist`,
+				`10:5: item.where: checking expression: unknown name qty (1:43)`,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			config, err := model.LoadConfig(strings.NewReader(c.config), ".")
+			if err != nil {
+				t.Fatalf("error loading config: %v", err)
+			}
+
+			err = Validate(config, c.files)
+			if len(c.expErrs) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			act := lo.Map(model.Errors(err), func(err error, _ int) string {
+				line, _, _ := strings.Cut(err.Error(), "\n")
+				return line
+			})
+			assert.Equal(t, c.expErrs, act)
+		})
+	}
+}
@@ -1,7 +1,8 @@
 package generator
 
 import (
-	"github.com/brianvoe/gofakeit/v7"
+	"math/rand/v2"
+
 	"github.com/samber/lo"
 )
 
@@ -27,8 +28,8 @@ func makeWeightedItems(items []weightedItem) weightedItems {
 	return wi
 }
 
-func (wi weightedItems) choose() string {
-	randomWeight := gofakeit.RandomInt([]int{1, wi.totalWeight})
+func (wi weightedItems) choose(r *rand.Rand) string {
+	randomWeight := r.IntN(wi.totalWeight) + 1
 	for _, i := range wi.items {
 		randomWeight -= i.Weight
 		if randomWeight <= 0 {
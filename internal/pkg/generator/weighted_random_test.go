@@ -3,6 +3,7 @@ package generator
 import (
 	"testing"
 
+	"github.com/codingconcepts/dg/internal/pkg/random"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -38,10 +39,11 @@ func TestChoose(t *testing.T) {
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
 			items := makeWeightedItems(c.items)
+			r := random.New()
 
 			var act []string
 			for i := 0; i < 10; i++ {
-				act = append(act, items.choose())
+				act = append(act, items.choose(r))
 			}
 
 			assert.Equal(t, c.exp, act)
@@ -0,0 +1,66 @@
+package generator
+
+import (
+	"fmt"
+
+	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/codingconcepts/dg/internal/pkg/random"
+	"github.com/samber/lo"
+)
+
+// FilterRows removes the rows of a generated table for which the table's
+// where expression is false. The expression is evaluated with the same
+// record as expr columns: the row's values (as strings), row_number and the
+// functions available to expressions.
+func FilterRows(t model.Table, files map[string]model.CSVFile) error {
+	if t.Where == "" {
+		return nil
+	}
+
+	file, ok := files[t.Name]
+	if !ok {
+		return fmt.Errorf("missing table: %q", t.Name)
+	}
+
+	rows := len(lo.MaxBy(file.Lines, func(a, b []string) bool {
+		return len(a) > len(b)
+	}))
+
+	// The expression has its own random source, as the table's count does,
+	// so that functions like rand don't change the values of any column.
+	ec := &ExprContext{Files: files, Rand: random.Derive(t.Name, "where", "")}
+
+	var keep []int
+	for row := 0; row < rows; row++ {
+		env := ec.makeEnv()
+		if err := ec.mergeEnv(env, file.GetRecord(row)); err != nil {
+			return err
+		}
+
+		output, err := ec.evaluate(t.Where, env)
+		if err != nil {
+			return fmt.Errorf("evaluating where for row %d: %w", row, err)
+		}
+		if ec.AnyToBool(output) {
+			keep = append(keep, row)
+		}
+	}
+
+	if len(keep) == rows {
+		return nil
+	}
+
+	lines := make([][]string, len(file.Lines))
+	for i, column := range file.Lines {
+		lines[i] = make([]string, 0, len(keep))
+		for _, row := range keep {
+			if row < len(column) {
+				lines[i] = append(lines[i], column[row])
+			}
+		}
+	}
+	file.Lines = lines
+	files[t.Name] = file
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterRows(t *testing.T) {
+	cases := []struct {
+		name     string
+		where    string
+		expLines [][]string
+		expErr   string
+	}{
+		{
+			name:  "no where expression",
+			where: "",
+			expLines: [][]string{
+				{"1", "2", "3", "4"},
+				{"2024-01-01", "2031-01-01", "2025-06-01", "2030-01-01"},
+				{"a", "b", "c"},
+			},
+		},
+		{
+			name:  "row values",
+			where: `date(placed) < date("2026-01-01")`,
+			expLines: [][]string{
+				{"1", "3"},
+				{"2024-01-01", "2025-06-01"},
+				{"a", "c"},
+			},
+		},
+		{
+			name:  "row number",
+			where: `row_number % 2 == 1`,
+			expLines: [][]string{
+				{"2", "4"},
+				{"2031-01-01", "2030-01-01"},
+				{"b"},
+			},
+		},
+		{
+			name:  "other tables",
+			where: `id in get_column("customer_order", "order_id")`,
+			expLines: [][]string{
+				{"2", "4"},
+				{"2031-01-01", "2030-01-01"},
+				{"b"},
+			},
+		},
+		{
+			name:  "no rows",
+			where: `false`,
+			expLines: [][]string{
+				{},
+				{},
+				{},
+			},
+		},
+		{
+			name:   "invalid expression",
+			where:  `id +`,
+			expErr: "evaluating where for row 0: error evaluating expression: unexpected token EOF (1:4)\n | id +\n | ...^",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			files := map[string]model.CSVFile{
+				"order": {
+					Name:   "order",
+					Header: []string{"id", "placed", "ragged"},
+					Lines: [][]string{
+						{"1", "2", "3", "4"},
+						{"2024-01-01", "2031-01-01", "2025-06-01", "2030-01-01"},
+						{"a", "b", "c"},
+					},
+				},
+				"customer_order": {
+					Name:   "customer_order",
+					Header: []string{"order_id"},
+					Lines:  [][]string{{"2", "4"}},
+				},
+			}
+
+			err := FilterRows(model.Table{Name: "order", Where: c.where}, files)
+			if c.expErr != "" {
+				assert.EqualError(t, err, c.expErr)
+				return
+			}
+			if err != nil {
+				t.Fatalf("error filtering rows: %v", err)
+			}
+			assert.Equal(t, c.expLines, files["order"].Lines)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CycleError is returned when dependencies are circular.
+type CycleError struct {
+	// Path holds the nodes in the cycle, with the first node repeated at the
+	// end.
+	Path []string
+}
+
+func (e CycleError) Error() string {
+	return fmt.Sprintf("dependency cycle detected: %s", strings.Join(e.Path, " -> "))
+}
+
+// Sort orders nodes so that every node comes after the nodes it depends on.
+// Nodes that don't depend on each other keep their original order, and
+// dependencies on nodes that aren't in the list (or on the node itself) are
+// ignored. An error describing the cycle is returned if the dependencies are
+// circular.
+func Sort(nodes []string, dependencies map[string][]string) ([]string, error) {
+	known := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		known[n] = true
+	}
+
+	sorted := make([]string, 0, len(nodes))
+	done := make(map[string]bool, len(nodes))
+
+	for len(sorted) < len(nodes) {
+		progress := false
+		for _, n := range nodes {
+			if done[n] || !ready(n, dependencies[n], known, done) {
+				continue
+			}
+
+			sorted = append(sorted, n)
+			done[n] = true
+			progress = true
+
+			// Start again from the first node, so that nodes unblocked by
+			// this one keep their original order.
+			break
+		}
+
+		if !progress {
+			return nil, CycleError{Path: findCycle(nodes, dependencies, known, done)}
+		}
+	}
+
+	return sorted, nil
+}
+
+// SortBy orders items with Sort, using the name of each item as its node
+// and the names returned by dependencies as the nodes it depends on. Items
+// must have unique names.
+func SortBy[T any](items []T, name func(T) string, dependencies func(T) []string) ([]T, error) {
+	nodes := make([]string, len(items))
+	byName := make(map[string]T, len(items))
+	deps := make(map[string][]string, len(items))
+	for i, item := range items {
+		n := name(item)
+		nodes[i] = n
+		byName[n] = item
+		deps[n] = dependencies(item)
+	}
+
+	sorted, err := Sort(nodes, deps)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]T, len(sorted))
+	for i, n := range sorted {
+		result[i] = byName[n]
+	}
+	return result, nil
+}
+
+func ready(node string, dependencies []string, known, done map[string]bool) bool {
+	for _, d := range dependencies {
+		if d != node && known[d] && !done[d] {
+			return false
+		}
+	}
+	return true
+}
+
+// findCycle returns a cycle between the nodes that couldn't be sorted, with
+// the first node repeated at the end.
+func findCycle(nodes []string, dependencies map[string][]string, known, done map[string]bool) []string {
+	var start string
+	for _, n := range nodes {
+		if !done[n] {
+			start = n
+			break
+		}
+	}
+
+	// Every unsorted node has an unsorted dependency, so following the first
+	// one from any node must eventually revisit a node.
+	var path []string
+	visited := map[string]int{}
+	for n := start; ; {
+		if i, ok := visited[n]; ok {
+			return append(path[i:], n)
+		}
+		visited[n] = len(path)
+		path = append(path, n)
+
+		for _, d := range dependencies[n] {
+			if d != n && known[d] && !done[d] {
+				n = d
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSort(t *testing.T) {
+	cases := []struct {
+		name         string
+		nodes        []string
+		dependencies map[string][]string
+		exp          []string
+		expErr       string
+	}{
+		{
+			name:  "no dependencies",
+			nodes: []string{"c", "a", "b"},
+			exp:   []string{"c", "a", "b"},
+		},
+		{
+			name:  "dependencies first",
+			nodes: []string{"person_event", "person", "event"},
+			dependencies: map[string][]string{
+				"person_event": {"person", "event"},
+			},
+			exp: []string{"person", "event", "person_event"},
+		},
+		{
+			name:  "chain",
+			nodes: []string{"c", "b", "a"},
+			dependencies: map[string][]string{
+				"c": {"b"},
+				"b": {"a"},
+			},
+			exp: []string{"a", "b", "c"},
+		},
+		{
+			name:  "unknown and self dependencies ignored",
+			nodes: []string{"employee", "team"},
+			dependencies: map[string][]string{
+				"employee": {"employee", "team", "input"},
+			},
+			exp: []string{"team", "employee"},
+		},
+		{
+			name:  "cycle",
+			nodes: []string{"x", "a", "b", "c"},
+			dependencies: map[string][]string{
+				"a": {"b"},
+				"b": {"c"},
+				"c": {"a"},
+			},
+			expErr: "dependency cycle detected: a -> b -> c -> a",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			act, err := Sort(c.nodes, c.dependencies)
+			if c.expErr != "" {
+				assert.EqualError(t, err, c.expErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.exp, act)
+		})
+	}
+}
+
+func TestSortBy(t *testing.T) {
+	type table struct {
+		name string
+		refs []string
+	}
+
+	tables := []table{
+		{name: "pet", refs: []string{"person"}},
+		{name: "person"},
+		{name: "toy", refs: []string{"pet"}},
+	}
+
+	act, err := SortBy(tables, func(t table) string {
+		return t.name
+	}, func(t table) []string {
+		return t.refs
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []table{tables[1], tables[0], tables[2]}, act)
+}
@@ -1,10 +1,17 @@
 package model
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"maps"
+	"math"
 	"os"
 	"path"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 
 	"github.com/samber/lo"
 	"gopkg.in/yaml.v3"
@@ -15,6 +22,15 @@ type Config struct {
 	Tables  []Table  `yaml:"tables"`
 	Inputs  []Input  `yaml:"inputs"`
 	Extends []string `yaml:"extends"`
+	Seed    *int64   `yaml:"seed"`
+
+	// Vars holds values that the config's counts, processors and sources
+	// refer to as ${var.name}, and that expressions read from vars.
+	Vars map[string]any `yaml:"vars"`
+
+	// File is the file the config was loaded from. Merged configs keep the
+	// file of the first config that has one.
+	File string `yaml:"-"`
 }
 
 // Table represents the instructions to create one CSV file.
@@ -22,16 +38,193 @@ type Table struct {
 	Name          string   `yaml:"name"`
 	Count         int      `yaml:"count"`
 	Suppress      bool     `yaml:"suppress"`
+	Fixed         bool     `yaml:"fixed"`
 	UniqueColumns []string `yaml:"unique_columns"`
+	UniqueMode    string   `yaml:"unique_mode"`
+	UniqueRetries int      `yaml:"unique_retries"`
 	Columns       []Column `yaml:"columns"`
+	Output        Output   `yaml:"output"`
+	Where         string   `yaml:"where"`
+	Merge         Merge    `yaml:"merge"`
+
+	// CountExpression and CountRange hold a count that's given as an
+	// expression or a range, which is resolved into Count once the tables
+	// that the table reads from have been generated.
+	CountExpression string      `yaml:"-"`
+	CountRange      *CountRange `yaml:"-"`
+
+	// Position is where the table is declared.
+	Position Position `yaml:"-"`
+}
+
+// CountRange is a range that a table's count is picked from at random,
+// including min and max.
+type CountRange struct {
+	Min int `yaml:"min"`
+	Max int `yaml:"max"`
+}
+
+// UnmarshalYAML decodes a table, keeping its position. A count can be a
+// number, an expression or a range ({min, max}).
+func (t *Table) UnmarshalYAML(value *yaml.Node) error {
+	value, err := t.unmarshalCount(value)
+	if err != nil {
+		return err
+	}
+
+	type table Table
+	if err := value.Decode((*table)(t)); err != nil {
+		return err
+	}
+	t.Position = nodePosition(value)
+
+	return checkMerge(value, map[string]string{
+		"columns":        t.Merge.Columns,
+		"unique_columns": t.Merge.UniqueColumns,
+	}, map[string][]string{
+		"columns":        {MergeReplace, MergePatch},
+		"unique_columns": {MergeUnion, MergeReplace},
+	})
+}
+
+// unmarshalCount decodes a count that's an expression or a range, returning
+// the table's node without it.
+func (t *Table) unmarshalCount(value *yaml.Node) (*yaml.Node, error) {
+	i := mappingIndex(value, "count")
+	if i == -1 {
+		return value, nil
+	}
+
+	count := value.Content[i+1]
+	switch {
+	case count.Kind == yaml.MappingNode:
+		var errs []string
+		for j := 0; j+1 < len(count.Content); j += 2 {
+			if key := count.Content[j]; key.Value != "min" && key.Value != "max" {
+				errs = append(errs, fmt.Sprintf("line %d: unknown count field %q (expected min or max)", key.Line, key.Value))
+			}
+		}
+		if len(errs) > 0 {
+			return nil, &yaml.TypeError{Errors: errs}
+		}
+
+		t.CountRange = &CountRange{}
+		if err := count.Decode(t.CountRange); err != nil {
+			return nil, err
+		}
+
+	case count.Kind == yaml.ScalarNode && count.ShortTag() == "!!str":
+		t.CountExpression = count.Value
+
+	default:
+		return value, nil
+	}
+
+	without := *value
+	without.Content = slices.Delete(slices.Clone(value.Content), i, i+2)
+	return &without, nil
+}
+
+// Merge represents how a table or input is merged with an earlier table or
+// input of the same name, from a file it extends or an earlier -c file.
+// Empty fields keep the default rules.
+type Merge struct {
+	// Columns is replace (the default) to replace all of a table's columns
+	// with the new table's, or patch to merge them by name.
+	Columns string `yaml:"columns"`
+
+	// UniqueColumns is union (the default) to add the new table's unique
+	// columns to the table's, or replace to replace them.
+	UniqueColumns string `yaml:"unique_columns"`
+
+	// Source is replace (the default) to replace an input's source with the
+	// new input's, or patch to only replace the fields the new input sets.
+	Source string `yaml:"source"`
+}
+
+const (
+	// MergeReplace replaces a table's columns, unique columns or an input's
+	// source.
+	MergeReplace = "replace"
+
+	// MergePatch merges columns by name and source fields by key.
+	MergePatch = "patch"
+
+	// MergeUnion adds unique columns to those of the table.
+	MergeUnion = "union"
+)
+
+// checkMerge returns an error for any merge fields with values that aren't
+// allowed. Errors are returned as yaml.TypeErrors, so that they're reported
+// with the rest of the document's errors.
+func checkMerge(value *yaml.Node, fields map[string]string, allowed map[string][]string) error {
+	var errs []string
+	for _, field := range lo.Keys(fields) {
+		if mode := fields[field]; mode != "" && !lo.Contains(allowed[field], mode) {
+			errs = append(errs, fmt.Sprintf("line %d: invalid merge %s %q (expected %s)", value.Line, field, mode, strings.Join(allowed[field], " or ")))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	slices.Sort(errs)
+	return &yaml.TypeError{Errors: errs}
+}
+
+const (
+	// UniqueDrop removes rows that duplicate the unique columns of an
+	// earlier row, so tables can end up with fewer rows than their count.
+	UniqueDrop = "drop"
+
+	// UniqueRetry regenerates rows that duplicate the unique columns of an
+	// earlier row, so tables keep their count.
+	UniqueRetry = "retry"
+)
+
+// Output represents the instructions for writing a table's file. Empty
+// fields fall back to the values provided on the command line.
+type Output struct {
+	Format string `yaml:"format"`
+	Empty  string `yaml:"empty"`
+}
+
+// Override returns a copy of o, with any fields set in other replacing
+// their counterparts in o.
+func (o Output) Override(other Output) Output {
+	if other.Format != "" {
+		o.Format = other.Format
+	}
+	if other.Empty != "" {
+		o.Empty = other.Empty
+	}
+	return o
 }
 
 // Column represents the instructions to populate one CSV file column.
 type Column struct {
-	Name      string     `yaml:"name"`
-	Type      string     `yaml:"type"`
-	Suppress  bool       `yaml:"suppress"`
-	Generator RawMessage `yaml:"processor"`
+	Name       string     `yaml:"name"`
+	Type       string     `yaml:"type"`
+	Suppress   bool       `yaml:"suppress"`
+	DataType   DataType   `yaml:"data_type"`
+	PrimaryKey bool       `yaml:"primary_key"`
+	Generator  RawMessage `yaml:"processor"`
+
+	// Remove removes a column of the same name from a table whose columns
+	// are patched.
+	Remove bool `yaml:"remove"`
+
+	// Position is where the column is declared.
+	Position Position `yaml:"-"`
+}
+
+// UnmarshalYAML decodes a column, keeping its position.
+func (c *Column) UnmarshalYAML(value *yaml.Node) error {
+	type column Column
+	if err := value.Decode((*column)(c)); err != nil {
+		return err
+	}
+	c.Position = nodePosition(value)
+	return nil
 }
 
 // Input represents a data source provided by the user.
@@ -39,35 +232,237 @@ type Input struct {
 	Name   string     `yaml:"name"`
 	Type   string     `yaml:"type"`
 	Source RawMessage `yaml:"source"`
+	Merge  Merge      `yaml:"merge"`
+
+	// Position is where the input is declared.
+	Position Position `yaml:"-"`
 }
 
-// Load config from a file
-func LoadConfig(r io.Reader, baseDir string) (Config, error) {
-	var c Config
-	if err := yaml.NewDecoder(r).Decode(&c); err != nil {
-		return Config{}, fmt.Errorf("parsing file: %w", err)
+// UnmarshalYAML decodes an input, keeping its position.
+func (i *Input) UnmarshalYAML(value *yaml.Node) error {
+	type input Input
+	if err := value.Decode((*input)(i)); err != nil {
+		return err
+	}
+	i.Position = nodePosition(value)
+
+	return checkMerge(value, map[string]string{
+		"source":         i.Merge.Source,
+		"columns":        i.Merge.Columns,
+		"unique_columns": i.Merge.UniqueColumns,
+	}, map[string][]string{
+		"source": {MergeReplace, MergePatch},
+	})
+}
+
+// LoadConfigFile loads a config from a file, along with any files it
+// extends, recording the file that each table, column and input came from.
+func LoadConfigFile(filename string) (Config, error) {
+	l := newConfigLoader(nil)
+
+	f, _, err := l.parseFile(filename)
+	if err != nil {
+		return Config{}, err
+	}
+	return l.decodeFile(f)
+}
+
+// LoadConfigFiles loads configs from files, along with any files they
+// extend, and merges them from left to right. A file that's extended by
+// more than one of the files is only merged the first time it's extended.
+// vars override the vars that the files declare.
+func LoadConfigFiles(filenames []string, vars map[string]any) (Config, error) {
+	l := newConfigLoader(vars)
+
+	// Parse every file before decoding any of them, so that the vars of
+	// later files apply to earlier ones.
+	var files []*configFile
+	for _, filename := range filenames {
+		f, ok, err := l.parseFile(filename)
+		if err != nil {
+			return Config{}, Position{File: filename}.Wrap(err)
+		}
+		if ok {
+			files = append(files, f)
+		}
+	}
+
+	var mergedConfig Config
+	for _, f := range files {
+		c, err := l.decodeFile(f)
+		if err != nil {
+			return Config{}, Position{File: f.filename}.Wrap(err)
+		}
+		mergedConfig = MergeConfig(mergedConfig, c)
+	}
+	mergedConfig.Vars = l.allVars()
+
+	return mergedConfig, nil
+}
+
+// configLoader loads configs and the files they extend, detecting files
+// that extend themselves and skipping files that have already been merged.
+// Configs are loaded in two passes: every file is parsed (collecting vars)
+// before any file is decoded, so that references to vars resolve to the
+// values declared by the files loaded last.
+type configLoader struct {
+	// stack holds the files being parsed, from the first file to the file
+	// that's currently being parsed.
+	stack []string
+
+	// loaded holds the canonical paths of the files that have been parsed.
+	loaded map[string]bool
+
+	// vars holds the vars declared by the files that have been parsed, and
+	// overrides holds the vars that override them.
+	vars      map[string]any
+	overrides map[string]any
+}
+
+// configFile is a parsed config that hasn't been decoded yet.
+type configFile struct {
+	filename string
+	node     yaml.Node
+
+	// extends holds the files that the config extends, leaving out files
+	// that had already been parsed.
+	extends []*configFile
+}
+
+func newConfigLoader(overrides map[string]any) *configLoader {
+	return &configLoader{
+		loaded:    map[string]bool{},
+		vars:      map[string]any{},
+		overrides: overrides,
+	}
+}
+
+// allVars returns the vars declared by the files parsed so far, with their
+// overrides.
+func (l *configLoader) allVars() map[string]any {
+	vars := maps.Clone(l.vars)
+	maps.Copy(vars, l.overrides)
+	return vars
+}
+
+// parseFile parses a config from a file, returning false if the file has
+// already been parsed.
+func (l *configLoader) parseFile(filename string) (*configFile, bool, error) {
+	canonical := canonicalPath(filename)
+	if i := slices.IndexFunc(l.stack, func(f string) bool { return canonicalPath(f) == canonical }); i != -1 {
+		return nil, false, ConfigError{
+			Position: Position{File: l.stack[len(l.stack)-1]},
+			Err:      extendsCycleError{chain: append(slices.Clone(l.stack[i:]), filename)},
+		}
+	}
+	if l.loaded[canonical] {
+		return nil, false, nil
+	}
+	l.loaded[canonical] = true
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, false, err
+	}
+	defer file.Close()
+
+	l.stack = append(l.stack, filename)
+	defer func() { l.stack = l.stack[:len(l.stack)-1] }()
+
+	f, err := l.parse(file, filename, path.Dir(filename))
+	if err != nil {
+		return nil, false, fileErrors(filename, err)
+	}
+
+	return f, true, nil
+}
+
+// parse parses a config and the files it extends, collecting their vars.
+// The vars of a config override those of the files it extends.
+func (l *configLoader) parse(r io.Reader, filename, baseDir string) (*configFile, error) {
+	f := &configFile{filename: filename}
+	if err := yaml.NewDecoder(r).Decode(&f.node); err != nil {
+		return nil, fmt.Errorf("parsing file: %w", err)
+	}
+
+	var header struct {
+		Extends []string       `yaml:"extends"`
+		Vars    map[string]any `yaml:"vars"`
+	}
+	if err := f.node.Decode(&header); err != nil {
+		return nil, fmt.Errorf("parsing file: %w", err)
 	}
 
 	// Process extends section if it exists
-	if len(c.Extends) > 0 {
-		var mergedConfig Config
-		for _, extendFile := range c.Extends {
-			fullPath := path.Join(baseDir, extendFile)
-			extFile, err := os.Open(fullPath)
+	if len(header.Extends) > 0 {
+		filenames, err := extendsFiles(baseDir, header.Extends, l.stack)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, fullPath := range filenames {
+			// Recursively parse extended config
+			ext, ok, err := l.parseFile(fullPath)
 			if err != nil {
-				return Config{}, fmt.Errorf("opening extended file %s: %w", fullPath, err)
+				// The chain of a cycle already says where it's extended from
+				if errors.As(err, &extendsCycleError{}) {
+					return nil, err
+				}
+				return nil, fmt.Errorf("loading extended config from %s: %w", fullPath, err)
 			}
-			defer extFile.Close()
 
-			// Recursively load extended config
-			extConfig, err := LoadConfig(extFile, path.Dir(fullPath))
-			if err != nil {
-				return Config{}, fmt.Errorf("loading extended config from %s: %w", fullPath, err)
+			// Skip files that have already been parsed (e.g. a base file
+			// extended by two files that are both extended)
+			if ok {
+				f.extends = append(f.extends, ext)
 			}
+		}
+	}
+
+	maps.Copy(l.vars, header.Vars)
+
+	return f, nil
+}
+
+// decodeFile decodes a parsed config from a file, recording the file that
+// each table, column and input came from.
+func (l *configLoader) decodeFile(f *configFile) (Config, error) {
+	c, err := l.decode(f)
+	if err != nil {
+		return Config{}, fileErrors(f.filename, err)
+	}
+	c.File = f.filename
+	c.setFile(f.filename)
+
+	return c, nil
+}
 
-			// Merge the extended config with current merged config
-			mergedConfig = MergeConfig(mergedConfig, extConfig)
+// decode decodes a parsed config once references to vars have been
+// replaced, merging it with the files it extends.
+func (l *configLoader) decode(f *configFile) (Config, error) {
+	var mergedConfig Config
+	for _, ext := range f.extends {
+		extConfig, err := l.decodeFile(ext)
+		if err != nil {
+			return Config{}, fmt.Errorf("loading extended config from %s: %w", ext.filename, err)
 		}
+
+		// Merge the extended config with current merged config
+		mergedConfig = MergeConfig(mergedConfig, extConfig)
+	}
+
+	// Replace references to vars, which can be declared by any of the files
+	// being loaded
+	if err := interpolateConfig(f.filename, &f.node, l.allVars()); err != nil {
+		return Config{}, err
+	}
+
+	var c Config
+	if err := f.node.Decode(&c); err != nil {
+		return Config{}, fmt.Errorf("parsing file: %w", err)
+	}
+
+	if len(c.Extends) > 0 {
 		// Finally merge with the current config
 		c = MergeConfig(mergedConfig, c)
 	}
@@ -75,16 +470,209 @@ func LoadConfig(r io.Reader, baseDir string) (Config, error) {
 	return c, nil
 }
 
+// fileErrors gives the errors of loading a file the file's position, unless
+// they already have a position.
+func fileErrors(filename string, err error) error {
+	var configErr ConfigError
+	if errors.As(err, &configErr) {
+		return err
+	}
+	return errors.Join(positionErrors(filename, err)...)
+}
+
+// extendsCycleError is returned for a file that extends itself, directly or
+// through the files it extends.
+type extendsCycleError struct {
+	chain []string
+}
+
+func (e extendsCycleError) Error() string {
+	return fmt.Sprintf("extends cycle: %s", strings.Join(e.chain, " -> "))
+}
+
+// extendsFiles returns the files that a config extends, relative to the
+// config's directory. Glob patterns are expanded into the files they match,
+// in lexical order, leaving out files that are being loaded (such as the
+// config itself).
+func extendsFiles(baseDir string, extends []string, loading []string) ([]string, error) {
+	var filenames []string
+	for _, extendFile := range extends {
+		fullPath := path.Join(baseDir, extendFile)
+		if !strings.ContainsAny(extendFile, "*?[") {
+			filenames = append(filenames, fullPath)
+			continue
+		}
+
+		matches, err := filepath.Glob(fullPath)
+		if err != nil {
+			return nil, fmt.Errorf("expanding extends pattern %q: %w", extendFile, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match extends pattern %q", extendFile)
+		}
+		for _, match := range matches {
+			if !slices.ContainsFunc(loading, func(f string) bool { return canonicalPath(f) == canonicalPath(match) }) {
+				filenames = append(filenames, match)
+			}
+		}
+	}
+	return filenames, nil
+}
+
+// canonicalPath returns the absolute path of a file with any symlinks
+// resolved, so that the same file is recognised however it's referred to.
+func canonicalPath(filename string) string {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return path.Clean(filename)
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+	return abs
+}
+
+// Scale multiplies the counts of the config's tables, and the bounds of
+// count ranges, by factor. Tables that are fixed, that have no count or
+// whose count is an expression are left as they are.
+func (c *Config) Scale(factor float64) {
+	for i, t := range c.Tables {
+		if t.Fixed {
+			continue
+		}
+		if t.Count > 0 {
+			c.Tables[i].Count = ScaleCount(t.Count, factor)
+		}
+		if t.CountRange != nil {
+			c.Tables[i].CountRange = &CountRange{
+				Min: ScaleCount(t.CountRange.Min, factor),
+				Max: ScaleCount(t.CountRange.Max, factor),
+			}
+		}
+	}
+}
+
+// ScaleCount multiplies a count by factor, rounding to the nearest whole
+// number. Positive counts are never scaled below 1.
+func ScaleCount(count int, factor float64) int {
+	if count <= 0 || factor == 1 {
+		return count
+	}
+	return max(int(math.Round(float64(count)*factor)), 1)
+}
+
+// setFile records file as the file of the config's tables, columns and
+// inputs that don't already have one.
+func (c *Config) setFile(file string) {
+	set := func(p *Position) {
+		if p.File == "" {
+			p.File = file
+		}
+	}
+
+	for i := range c.Inputs {
+		set(&c.Inputs[i].Position)
+		set(&c.Inputs[i].Source.Position)
+	}
+	for i := range c.Tables {
+		t := &c.Tables[i]
+		set(&t.Position)
+		for j := range t.Columns {
+			set(&t.Columns[j].Position)
+			set(&t.Columns[j].Generator.Position)
+		}
+	}
+}
+
+// CheckConfigFile returns an error for each field of a config file, and the
+// files it extends, that isn't a field of a config, table, column, input or
+// output. Problems are returned as ConfigErrors, joined with errors.Join.
+func CheckConfigFile(filename string) error {
+	return checkConfigFile(filename, map[string]bool{})
+}
+
+func checkConfigFile(filename string, checked map[string]bool) error {
+	canonical := canonicalPath(filename)
+	if checked[canonical] {
+		return nil
+	}
+	checked[canonical] = true
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return ConfigError{Position: Position{File: filename}, Err: err}
+	}
+
+	var node yaml.Node
+	if err = yaml.Unmarshal(data, &node); err != nil {
+		return errors.Join(positionErrors(filename, err)...)
+	}
+
+	errs := unknownFields(filename, &node, reflect.TypeOf(Config{}))
+
+	var c struct {
+		Extends []string `yaml:"extends"`
+	}
+	if err = node.Decode(&c); err == nil {
+		filenames, err := extendsFiles(path.Dir(filename), c.Extends, []string{filename})
+		if err != nil {
+			errs = append(errs, ConfigError{Position: Position{File: filename}, Err: err})
+		}
+		for _, extendFile := range filenames {
+			errs = append(errs, checkConfigFile(extendFile, checked))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Load config from a file
+func LoadConfig(r io.Reader, baseDir string) (Config, error) {
+	l := newConfigLoader(nil)
+
+	f, err := l.parse(r, "", baseDir)
+	if err != nil {
+		return Config{}, err
+	}
+	return l.decode(f)
+}
+
 func MergeConfig(current Config, partial Config) Config {
+	// Copy the tables and inputs, so that merging doesn't change current.
 	result := current
+	result.Inputs = slices.Clone(current.Inputs)
+	result.Tables = slices.Clone(current.Tables)
+
+	if result.File == "" {
+		result.File = partial.File
+	}
+
+	// Rule: vars are merged by name, with new values overriding old ones.
+	if len(partial.Vars) > 0 {
+		result.Vars = maps.Clone(current.Vars)
+		if result.Vars == nil {
+			result.Vars = map[string]any{}
+		}
+		maps.Copy(result.Vars, partial.Vars)
+	}
+
+	// Rule: a seed (including 0) always overrides the previous seed.
+	if partial.Seed != nil {
+		result.Seed = partial.Seed
+	}
 
 	// Merge Inputs
 	for _, newInput := range partial.Inputs {
 		found := false
 		for i, existingInput := range result.Inputs {
 			if existingInput.Name == newInput.Name {
-				// Replace the entire Input if names match
-				result.Inputs[i] = newInput
+				if newInput.Merge.Source == MergePatch {
+					// Patch the source's fields if asked to
+					result.Inputs[i] = patchInput(existingInput, newInput)
+				} else {
+					// Replace the entire Input if names match
+					result.Inputs[i] = newInput
+				}
 				found = true
 				break
 			}
@@ -103,28 +691,103 @@ func MergeConfig(current Config, partial Config) Config {
 				tableFound = true
 				// Rule: the new count always overrides previous table count.
 				result.Tables[i].Count = overrideTable.Count
+				result.Tables[i].CountExpression = overrideTable.CountExpression
+				result.Tables[i].CountRange = overrideTable.CountRange
 				// Rule: the new suppress always overrides previous table suppress flag.
 				result.Tables[i].Suppress = overrideTable.Suppress
+				// Rule: the new fixed flag always overrides previous table fixed flag.
+				result.Tables[i].Fixed = overrideTable.Fixed
+				// Rule: output options are only overridden if provided.
+				result.Tables[i].Output = result.Tables[i].Output.Override(overrideTable.Output)
 
-				// Rule: if new columns exist, replace the entire column spec
-				if len(overrideTable.Columns) > 0 {
-					// Use append to replace current columns
-					result.Tables[i].Columns = append(result.Tables[i].Columns[:0], overrideTable.Columns...)
+				// Rule: if new columns exist, replace the entire column spec,
+				// unless the columns are to be patched by name
+				if overrideTable.Merge.Columns == MergePatch {
+					result.Tables[i].Columns = patchColumns(result.Tables[i].Columns, overrideTable.Columns)
+				} else if len(overrideTable.Columns) > 0 {
+					result.Tables[i].Columns = withoutRemoved(overrideTable.Columns)
 				}
 
-				// Rule: merge UniqueColumns Filtering columns that dont exists in final result
+				// Rule: the where expression is only overridden if provided.
+				if overrideTable.Where != "" {
+					result.Tables[i].Where = overrideTable.Where
+				}
+
+				// Rule: the unique mode and retries are only overridden if provided.
+				if overrideTable.UniqueMode != "" {
+					result.Tables[i].UniqueMode = overrideTable.UniqueMode
+				}
+				if overrideTable.UniqueRetries != 0 {
+					result.Tables[i].UniqueRetries = overrideTable.UniqueRetries
+				}
+
+				// Rule: merge UniqueColumns Filtering columns that dont exists in final result,
+				// unless they're to be replaced
 				if overrideTable.UniqueColumns != nil {
-					combined := append(result.Tables[i].UniqueColumns, overrideTable.UniqueColumns...)
-					result.Tables[i].UniqueColumns = lo.Uniq(combined)
+					if overrideTable.Merge.UniqueColumns == MergeReplace {
+						result.Tables[i].UniqueColumns = overrideTable.UniqueColumns
+					} else {
+						combined := append(result.Tables[i].UniqueColumns, overrideTable.UniqueColumns...)
+						result.Tables[i].UniqueColumns = lo.Uniq(combined)
+					}
 				}
 				break
 			}
 		}
 		// Rule: new tables are added to the config
 		if !tableFound {
+			overrideTable.Columns = withoutRemoved(overrideTable.Columns)
 			result.Tables = append(result.Tables, overrideTable)
 		}
 	}
 
 	return result
 }
+
+// patchColumns merges columns into a table's columns by name. Columns with
+// the name of an existing column replace it, or remove it if they're marked
+// remove, and other columns are added to the end.
+func patchColumns(columns, patch []Column) []Column {
+	result := slices.Clone(columns)
+	for _, c := range patch {
+		i := slices.IndexFunc(result, func(existing Column) bool {
+			return existing.Name == c.Name
+		})
+
+		switch {
+		case c.Remove:
+			if i != -1 {
+				result = slices.Delete(result, i, i+1)
+			}
+		case i != -1:
+			result[i] = c
+		default:
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// withoutRemoved returns the columns that aren't marked remove, which only
+// have an effect when patching columns.
+func withoutRemoved(columns []Column) []Column {
+	if !slices.ContainsFunc(columns, func(c Column) bool { return c.Remove }) {
+		return columns
+	}
+	return lo.Reject(columns, func(c Column, _ int) bool {
+		return c.Remove
+	})
+}
+
+// patchInput patches an input's source with the fields of another input of
+// the same name. Inputs of a different type replace the input.
+func patchInput(input, patch Input) Input {
+	if patch.Type != "" && patch.Type != input.Type {
+		return patch
+	}
+
+	if patch.Source.UnmarshalFunc != nil {
+		input.Source = input.Source.Patch(patch.Source)
+	}
+	return input
+}
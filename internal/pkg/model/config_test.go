@@ -1,6 +1,8 @@
 package model
 
 import (
+	"os"
+	"path"
 	"strings"
 	"testing"
 
@@ -279,3 +281,506 @@ tables:
 		assert.Equal(t, expectedColumns, actualColumns)
 	}
 }
+
+func TestMergeConfigOutput(t *testing.T) {
+	base := Config{
+		Tables: []Table{
+			{Name: "person", Output: Output{Format: "json", Empty: "omit"}},
+		},
+	}
+	override := Config{
+		Tables: []Table{
+			{Name: "person", Output: Output{Format: "ndjson"}},
+		},
+	}
+
+	merged := MergeConfig(base, override)
+	assert.Equal(t, Output{Format: "ndjson", Empty: "omit"}, merged.Tables[0].Output)
+}
+
+func TestMergeConfigUnique(t *testing.T) {
+	base := Config{
+		Tables: []Table{
+			{Name: "person", UniqueMode: UniqueRetry, UniqueRetries: 10},
+			{Name: "pet", UniqueMode: UniqueRetry},
+		},
+	}
+	override := Config{
+		Tables: []Table{
+			{Name: "person", UniqueRetries: 50},
+			{Name: "pet", UniqueMode: UniqueDrop},
+		},
+	}
+
+	merged := MergeConfig(base, override)
+	assert.Equal(t, UniqueRetry, merged.Tables[0].UniqueMode)
+	assert.Equal(t, 50, merged.Tables[0].UniqueRetries)
+	assert.Equal(t, UniqueDrop, merged.Tables[1].UniqueMode)
+}
+
+func TestMergeConfigColumns(t *testing.T) {
+	base := `
+tables:
+  - name: person
+    unique_columns: [id, email]
+    columns:
+      - name: id
+        type: inc
+      - name: email
+        type: gen
+      - name: age
+        type: rand`
+
+	cases := []struct {
+		name       string
+		override   string
+		expColumns []string
+		expTypes   []string
+		expUnique  []string
+	}{
+		{
+			name: "replace by default",
+			override: `
+tables:
+  - name: person
+    unique_columns: [name]
+    columns:
+      - name: name
+        type: gen`,
+			expColumns: []string{"name"},
+			expTypes:   []string{"gen"},
+			expUnique:  []string{"id", "email", "name"},
+		},
+		{
+			name: "patch",
+			override: `
+tables:
+  - name: person
+    merge:
+      columns: patch
+    columns:
+      - name: email
+        type: expr
+      - name: age
+        remove: true
+      - name: name
+        type: gen
+      - name: missing
+        remove: true`,
+			expColumns: []string{"id", "email", "name"},
+			expTypes:   []string{"inc", "expr", "gen"},
+			expUnique:  []string{"id", "email"},
+		},
+		{
+			name: "replace unique columns",
+			override: `
+tables:
+  - name: person
+    merge:
+      columns: patch
+      unique_columns: replace
+    unique_columns: [age]`,
+			expColumns: []string{"id", "email", "age"},
+			expTypes:   []string{"inc", "gen", "rand"},
+			expUnique:  []string{"age"},
+		},
+		{
+			name: "removed columns of new tables",
+			override: `
+tables:
+  - name: pet
+    columns:
+      - name: id
+        remove: true`,
+			expColumns: []string{"id", "email", "age"},
+			expTypes:   []string{"inc", "gen", "rand"},
+			expUnique:  []string{"id", "email"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			baseConfig, err := LoadConfig(strings.NewReader(base), ".")
+			if err != nil {
+				t.Fatalf("error loading base config: %v", err)
+			}
+			overrideConfig, err := LoadConfig(strings.NewReader(c.override), ".")
+			if err != nil {
+				t.Fatalf("error loading override config: %v", err)
+			}
+
+			merged := MergeConfig(baseConfig, overrideConfig)
+			person := merged.Tables[0]
+
+			assert.Equal(t, c.expColumns, lo.Map(person.Columns, func(c Column, _ int) string { return c.Name }))
+			assert.Equal(t, c.expTypes, lo.Map(person.Columns, func(c Column, _ int) string { return c.Type }))
+			assert.Equal(t, c.expUnique, person.UniqueColumns)
+			for _, table := range merged.Tables {
+				for _, column := range table.Columns {
+					assert.False(t, column.Remove)
+				}
+			}
+		})
+	}
+
+	// The base config's columns are left as they were.
+	baseConfig, _ := LoadConfig(strings.NewReader(base), ".")
+	patch := Config{Tables: []Table{{Name: "person", Merge: Merge{Columns: MergePatch}, Columns: []Column{{Name: "id", Remove: true}}}}}
+	MergeConfig(baseConfig, patch)
+	assert.Len(t, baseConfig.Tables[0].Columns, 3)
+}
+
+func TestMergeConfigInputs(t *testing.T) {
+	base := `
+inputs:
+  - name: market
+    type: csv
+    source:
+      file_name: market.csv
+      delimiter: ";"`
+
+	cases := []struct {
+		name      string
+		override  string
+		expType   string
+		expSource map[string]string
+	}{
+		{
+			name: "replace by default",
+			override: `
+inputs:
+  - name: market
+    type: csv
+    source:
+      file_name: other.csv`,
+			expType:   "csv",
+			expSource: map[string]string{"file_name": "other.csv"},
+		},
+		{
+			name: "patch",
+			override: `
+inputs:
+  - name: market
+    merge:
+      source: patch
+    source:
+      file_name: other.csv
+      quote: "'"`,
+			expType:   "csv",
+			expSource: map[string]string{"file_name": "other.csv", "delimiter": ";", "quote": "'"},
+		},
+		{
+			name: "patch with another type",
+			override: `
+inputs:
+  - name: market
+    type: tsv
+    merge:
+      source: patch
+    source:
+      file_name: other.tsv`,
+			expType:   "tsv",
+			expSource: map[string]string{"file_name": "other.tsv"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			baseConfig, err := LoadConfig(strings.NewReader(base), ".")
+			if err != nil {
+				t.Fatalf("error loading base config: %v", err)
+			}
+			overrideConfig, err := LoadConfig(strings.NewReader(c.override), ".")
+			if err != nil {
+				t.Fatalf("error loading override config: %v", err)
+			}
+
+			merged := MergeConfig(baseConfig, overrideConfig)
+			assert.Equal(t, c.expType, merged.Inputs[0].Type)
+
+			var source map[string]string
+			if err := merged.Inputs[0].Source.UnmarshalFunc(&source); err != nil {
+				t.Fatalf("error decoding source: %v", err)
+			}
+			assert.Equal(t, c.expSource, source)
+		})
+	}
+}
+
+func TestLoadConfigInvalidMerge(t *testing.T) {
+	y := `
+inputs:
+  - name: market
+    merge:
+      source: union
+tables:
+  - name: person
+    merge:
+      columns: union
+      unique_columns: patch`
+
+	_, err := LoadConfig(strings.NewReader(y), ".")
+	assert.Equal(t, []string{
+		`parsing file: yaml: unmarshal errors:`,
+		`  line 3: invalid merge source "union" (expected replace or patch)`,
+		`  line 7: invalid merge columns "union" (expected replace or patch)`,
+		`  line 7: invalid merge unique_columns "patch" (expected union or replace)`,
+	}, strings.Split(err.Error(), "\n"))
+}
+
+func TestLoadConfigFileExtends(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		filename := path.Join(dir, name)
+		if err := os.MkdirAll(path.Dir(filename), 0755); err != nil {
+			t.Fatalf("error creating directory: %v", err)
+		}
+		if err := os.WriteFile(filename, []byte(strings.TrimPrefix(content, "\n")), 0644); err != nil {
+			t.Fatalf("error writing file: %v", err)
+		}
+		return filename
+	}
+
+	write("diamond/base.yaml", `
+tables:
+  - name: person
+    count: 1
+  - name: pet
+    count: 1
+`)
+	write("diamond/people.yaml", `
+extends: [base.yaml]
+tables:
+  - name: person
+    count: 10
+`)
+	write("diamond/pets.yaml", `
+extends: [./base.yaml]
+tables:
+  - name: pet
+    count: 20
+`)
+	diamond := write("diamond/config.yaml", `
+extends: [people.yaml, pets.yaml]
+`)
+
+	write("cycle/a.yaml", "extends: [b.yaml]\n")
+	write("cycle/b.yaml", "extends: [c.yaml]\n")
+	write("cycle/c.yaml", "extends: [a.yaml]\n")
+
+	write("glob/tables/person.yaml", `
+tables:
+  - name: person
+    count: 10
+`)
+	write("glob/tables/pet.yaml", `
+extends: ["*.yaml"]
+tables:
+  - name: pet
+    count: 20
+`)
+	glob := write("glob/config.yaml", `
+extends: [tables/*.yaml]
+tables:
+  - name: pet
+    count: 30
+`)
+	missing := write("glob/missing.yaml", "extends: [nowhere/*.yaml]\n")
+
+	t.Run("diamond", func(t *testing.T) {
+		c, err := LoadConfigFile(diamond)
+		if err != nil {
+			t.Fatalf("error loading config: %v", err)
+		}
+
+		// The base is merged once, so it doesn't undo the counts of
+		// the file merged before the second file that extends it.
+		assert.Equal(t, map[string]int{"person": 10, "pet": 20}, counts(c))
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		_, err := LoadConfigFile(path.Join(dir, "cycle/a.yaml"))
+
+		cycle := path.Join(dir, "cycle")
+		assert.EqualError(t, err, strings.ReplaceAll(
+			"$/c.yaml: extends cycle: $/a.yaml -> $/b.yaml -> $/c.yaml -> $/a.yaml", "$", cycle))
+	})
+
+	t.Run("glob", func(t *testing.T) {
+		c, err := LoadConfigFile(glob)
+		if err != nil {
+			t.Fatalf("error loading config: %v", err)
+		}
+		assert.Equal(t, map[string]int{"person": 10, "pet": 30}, counts(c))
+		assert.Equal(t, []string{"person", "pet"}, lo.Map(c.Tables, func(t Table, _ int) string { return t.Name }))
+	})
+
+	t.Run("glob without matches", func(t *testing.T) {
+		_, err := LoadConfigFile(missing)
+		assert.EqualError(t, err, missing+`: no files match extends pattern "nowhere/*.yaml"`)
+	})
+
+	t.Run("multiple files", func(t *testing.T) {
+		c, err := LoadConfigFiles([]string{
+			path.Join(dir, "diamond/people.yaml"),
+			path.Join(dir, "diamond/pets.yaml"),
+		}, nil)
+		if err != nil {
+			t.Fatalf("error loading configs: %v", err)
+		}
+		assert.Equal(t, map[string]int{"person": 10, "pet": 20}, counts(c))
+	})
+}
+
+func counts(c Config) map[string]int {
+	return lo.SliceToMap(c.Tables, func(t Table) (string, int) {
+		return t.Name, t.Count
+	})
+}
+
+func TestConfigScale(t *testing.T) {
+	c := Config{
+		Tables: []Table{
+			{Name: "person", Count: 1000},
+			{Name: "small", Count: 3},
+			{Name: "market", Count: 50, Fixed: true},
+			{Name: "pet"},
+		},
+	}
+
+	c.Scale(0.1)
+	assert.Equal(t, map[string]int{"person": 100, "small": 1, "market": 50, "pet": 0}, counts(c))
+
+	c.Scale(25)
+	assert.Equal(t, map[string]int{"person": 2500, "small": 25, "market": 50, "pet": 0}, counts(c))
+}
+
+func TestScaleCount(t *testing.T) {
+	cases := []struct {
+		count  int
+		factor float64
+		exp    int
+	}{
+		{count: 10, factor: 1, exp: 10},
+		{count: 10, factor: 0.25, exp: 3},
+		{count: 10, factor: 0.01, exp: 1},
+		{count: 10, factor: 2.5, exp: 25},
+		{count: 0, factor: 10, exp: 0},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.exp, ScaleCount(c.count, c.factor), "%d * %v", c.count, c.factor)
+	}
+}
+
+func TestLoadConfigCount(t *testing.T) {
+	y := `
+tables:
+  - name: person
+    count: 10
+  - name: pet
+    count: len(get_column("person", "id")) * 3
+  - name: toy
+    count:
+      min: 1
+      max: 5
+  - name: shop
+    columns:
+      - name: count
+        type: inc`
+
+	c, err := LoadConfig(strings.NewReader(y), ".")
+	if err != nil {
+		t.Fatalf("error loading config: %v", err)
+	}
+
+	assert.Equal(t, 10, c.Tables[0].Count)
+	assert.Equal(t, `len(get_column("person", "id")) * 3`, c.Tables[1].CountExpression)
+	assert.Equal(t, &CountRange{Min: 1, Max: 5}, c.Tables[2].CountRange)
+	assert.Nil(t, c.Tables[3].CountRange)
+	assert.Equal(t, "count", c.Tables[3].Columns[0].Name)
+	for _, table := range c.Tables {
+		assert.NotZero(t, table.Position.Line)
+	}
+}
+
+func TestLoadConfigCountErrors(t *testing.T) {
+	y := `
+tables:
+  - name: person
+    count:
+      min: 1
+      maximum: 5
+  - name: pet
+    count: {min: [1]}`
+
+	_, err := LoadConfig(strings.NewReader(y), ".")
+	assert.Equal(t, []string{
+		`parsing file: yaml: unmarshal errors:`,
+		`  line 6: unknown count field "maximum" (expected min or max)`,
+		`  line 8: cannot unmarshal !!seq into int`,
+	}, strings.Split(err.Error(), "\n"))
+}
+
+func TestMergeConfigSeed(t *testing.T) {
+	base := Config{Seed: lo.ToPtr(int64(42))}
+
+	merged := MergeConfig(base, Config{})
+	assert.Equal(t, lo.ToPtr(int64(42)), merged.Seed)
+
+	merged = MergeConfig(base, Config{Seed: lo.ToPtr(int64(0))})
+	assert.Equal(t, lo.ToPtr(int64(0)), merged.Seed)
+}
+
+func TestMergeConfigWhere(t *testing.T) {
+	base := Config{
+		Tables: []Table{
+			{Name: "order", Where: "placed < now()"},
+			{Name: "item", Where: "qty > 0"},
+		},
+	}
+	override := Config{
+		Tables: []Table{
+			{Name: "order", Count: 10},
+			{Name: "item", Where: "qty > 1"},
+		},
+	}
+
+	merged := MergeConfig(base, override)
+	assert.Equal(t, "placed < now()", merged.Tables[0].Where)
+	assert.Equal(t, "qty > 1", merged.Tables[1].Where)
+}
+
+func TestMergeConfigCount(t *testing.T) {
+	base := Config{
+		Tables: []Table{
+			{Name: "person", Count: 10},
+			{Name: "pet", CountExpression: "len(get_column('person', 'id'))"},
+		},
+	}
+	override := Config{
+		Tables: []Table{
+			{Name: "person", CountRange: &CountRange{Min: 5, Max: 10}},
+			{Name: "pet", Count: 3},
+		},
+	}
+
+	merged := MergeConfig(base, override)
+	assert.Equal(t, Table{Name: "person", CountRange: &CountRange{Min: 5, Max: 10}}, merged.Tables[0])
+	assert.Equal(t, Table{Name: "pet", Count: 3}, merged.Tables[1])
+}
+
+func TestConfigScaleCount(t *testing.T) {
+	c := Config{
+		Tables: []Table{
+			{Name: "person", CountRange: &CountRange{Min: 10, Max: 20}},
+			{Name: "pet", CountExpression: "len(get_column('person', 'id'))"},
+			{Name: "market", CountRange: &CountRange{Min: 10, Max: 20}, Fixed: true},
+		},
+	}
+
+	c.Scale(0.5)
+	assert.Equal(t, &CountRange{Min: 5, Max: 10}, c.Tables[0].CountRange)
+	assert.Equal(t, "len(get_column('person', 'id'))", c.Tables[1].CountExpression)
+	assert.Equal(t, &CountRange{Min: 10, Max: 20}, c.Tables[2].CountRange)
+}
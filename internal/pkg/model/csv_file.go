@@ -1,6 +1,9 @@
 package model
 
 import (
+	"encoding/binary"
+	"hash/maphash"
+	"sync"
 	"time"
 
 	"github.com/samber/lo"
@@ -19,25 +22,118 @@ type CSVFile struct {
 	Lines         [][]string
 	UniqueColumns []string
 	Output        bool
+
+	// indexes caches the indexes of the file's columns.
+	indexes *indexCache
 }
 
-// Unique removes any duplicates from the CSVFile's lines.
-func (c *CSVFile) Unique() [][]string {
-	uniqueColumnIndexes := uniqueIndexes(c.Header, c.UniqueColumns)
+type indexCache struct {
+	mu      sync.Mutex
+	columns map[string]columnIndex
+}
 
-	uniqueValues := map[string]struct{}{}
-	var uniqueLines [][]string
+// columnIndex maps the values of a column to the rows they appear in.
+type columnIndex struct {
+	// values is the column the index was built from, which is used to
+	// detect columns that have since been replaced.
+	values    []string
+	positions map[string][]int
+}
+
+func (ci columnIndex) builtFrom(values []string) bool {
+	if len(ci.values) != len(values) {
+		return false
+	}
+	return len(values) == 0 || &ci.values[0] == &values[0]
+}
 
-	for i := 0; i < len(c.Lines); i++ {
-		key := uniqueKey(uniqueColumnIndexes, c.Lines[i])
+// GetIndex returns the index of a table's column (see CSVFile.Index), keeping
+// the file's cache of indexes in files so that it's reused by later calls.
+func GetIndex(table string, column string, files map[string]CSVFile) (map[string][]int, bool) {
+	refFile, ok := files[table]
+	if !ok {
+		return nil, false
+	}
+	cached := refFile.indexes != nil
+	index, ok := refFile.Index(column)
+	if !cached {
+		files[table] = refFile
+	}
+	return index, ok
+}
 
-		if _, ok := uniqueValues[key]; !ok {
-			uniqueLines = append(uniqueLines, c.Lines[i])
-			uniqueValues[key] = struct{}{}
+// CacheIndexes gives every file a cache of indexes, so that GetIndex doesn't
+// write to files and can be called from multiple goroutines.
+func CacheIndexes(files map[string]CSVFile) {
+	for name, file := range files {
+		if file.indexes == nil {
+			file.indexes = &indexCache{columns: map[string]columnIndex{}}
+			files[name] = file
 		}
 	}
+}
+
+// Index returns a map of a column's values to the rows they appear in, in
+// ascending order. The bool result is false if the column doesn't exist.
+//
+// Indexes are built on first use and cached until the column is replaced.
+// The cache is shared by copies of the file made after it's created.
+func (c *CSVFile) Index(column string) (map[string][]int, bool) {
+	i := lo.IndexOf(c.Header, column)
+	if i == -1 || i >= len(c.Lines) {
+		return nil, false
+	}
+	values := c.Lines[i]
+
+	if c.indexes == nil {
+		c.indexes = &indexCache{columns: map[string]columnIndex{}}
+	}
+
+	c.indexes.mu.Lock()
+	defer c.indexes.mu.Unlock()
+
+	index, ok := c.indexes.columns[column]
+	if !ok || !index.builtFrom(values) {
+		index = buildIndex(values)
+		c.indexes.columns[column] = index
+	}
+	return index.positions, true
+}
 
-	return uniqueLines
+func buildIndex(values []string) columnIndex {
+	positions := map[string][]int{}
+	for i, v := range values {
+		positions[v] = append(positions[v], i)
+	}
+	return columnIndex{values: values, positions: positions}
+}
+
+// Unique returns the CSVFile's lines without the rows whose unique columns
+// match those of an earlier row, keeping rows in the order they first
+// appear. Lines are read, and returned, as columns.
+func (c *CSVFile) Unique() [][]string {
+	rows := 0
+	for _, column := range c.Lines {
+		rows = max(rows, len(column))
+	}
+
+	seen := NewRowSet(c.Lines, uniqueIndexes(c.Header, c.UniqueColumns))
+	keep := make([]int, 0, rows)
+	for row := 0; row < rows; row++ {
+		if seen.Add(row) {
+			keep = append(keep, row)
+		}
+	}
+
+	lines := make([][]string, len(c.Lines))
+	for i, column := range c.Lines {
+		lines[i] = make([]string, len(keep))
+		for j, row := range keep {
+			lines[i][j] = cell(column, row)
+		}
+	}
+
+	return lines
 }
 
 func uniqueIndexes(header, uniqueColumns []string) []int {
@@ -52,18 +148,89 @@ func uniqueIndexes(header, uniqueColumns []string) []int {
 	return indexes
 }
 
-func uniqueKey(indexes []int, line []string) string {
-	output := ""
+// RowSet records the rows of columnar lines whose values in a set of
+// columns haven't been seen in an earlier row. Rows are hashed on just those
+// columns, and rows with the same hash are compared value by value, so rows
+// are only treated as duplicates if their values match.
+type RowSet struct {
+	lines   [][]string
+	columns []int
+	seed    maphash.Seed
 
-	for i, col := range line {
-		if lo.Contains(indexes, i) {
-			output += col
-		} else {
-			output += "-"
+	// first holds the first row added with each hash and others holds any
+	// later rows with the same hash but different values.
+	first  map[uint64]int
+	others map[uint64][]int
+}
+
+// NewRowSet returns an empty RowSet over the given columns of lines.
+func NewRowSet(lines [][]string, columns []int) *RowSet {
+	return &RowSet{
+		lines:   lines,
+		columns: columns,
+		seed:    maphash.MakeSeed(),
+		first:   map[uint64]int{},
+		others:  map[uint64][]int{},
+	}
+}
+
+// Add adds a row to the set and returns true, or returns false without
+// adding it if an earlier row has the same values. Rows that have been added
+// must not change.
+func (s *RowSet) Add(row int) bool {
+	return s.add(s.hash(row), row)
+}
+
+func (s *RowSet) add(key uint64, row int) bool {
+	first, ok := s.first[key]
+	if !ok {
+		s.first[key] = row
+		return true
+	}
+	if s.equal(first, row) {
+		return false
+	}
+	for _, other := range s.others[key] {
+		if s.equal(other, row) {
+			return false
 		}
 	}
 
-	return output
+	s.others[key] = append(s.others[key], row)
+	return true
+}
+
+// hash hashes the values of a row, prefixing each with its length so that
+// values can't run into one another (e.g. "ab", "c" and "a", "bc").
+func (s *RowSet) hash(row int) uint64 {
+	var h maphash.Hash
+	h.SetSeed(s.seed)
+
+	var length [binary.MaxVarintLen64]byte
+	for _, i := range s.columns {
+		value := cell(s.lines[i], row)
+		h.Write(length[:binary.PutUvarint(length[:], uint64(len(value)))])
+		h.WriteString(value)
+	}
+	return h.Sum64()
+}
+
+func (s *RowSet) equal(a, b int) bool {
+	for _, i := range s.columns {
+		if cell(s.lines[i], a) != cell(s.lines[i], b) {
+			return false
+		}
+	}
+	return true
+}
+
+// cell returns the value of a column at the given row, or an empty string if
+// the column is shorter than that.
+func cell(column []string, row int) string {
+	if row < len(column) {
+		return column[row]
+	}
+	return ""
 }
 
 func GetRecord(table string, lineNumber int, files map[string]CSVFile) map[string]any {
@@ -1,8 +1,10 @@
 package model
 
 import (
+	"reflect"
 	"testing"
 
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -107,13 +109,67 @@ func TestUnique(t *testing.T) {
 				},
 			}
 
+			file.Lines = transpose(file.Lines)
 			act := file.Unique()
 
-			assert.Equal(t, c.exp, act)
+			assert.Equal(t, c.exp, transpose(act))
 		})
 	}
 }
 
+func TestUniqueLengthPrefixed(t *testing.T) {
+	file := CSVFile{
+		Header:        []string{"col_1", "col_2"},
+		UniqueColumns: []string{"col_1", "col_2"},
+		Lines: [][]string{
+			{"ab", "a", "ab", "a"},
+			{"c", "bc", "c", "bc"},
+		},
+	}
+
+	assert.Equal(t, [][]string{{"ab", "a"}, {"c", "bc"}}, file.Unique())
+}
+
+func TestUniqueRaggedColumns(t *testing.T) {
+	file := CSVFile{
+		Header:        []string{"col_1", "col_2"},
+		UniqueColumns: []string{"col_1"},
+		Lines: [][]string{
+			{"a", "b", "a", "c"},
+			{"1", "2"},
+		},
+	}
+
+	assert.Equal(t, [][]string{{"a", "b", "c"}, {"1", "2", ""}}, file.Unique())
+}
+
+func TestRowSetHashCollisions(t *testing.T) {
+	lines := [][]string{{"a", "b", "a", "c", "b"}}
+	s := NewRowSet(lines, []int{0})
+
+	// Give every row the same hash, so that rows are told apart by value.
+	added := lo.Times(len(lines[0]), func(row int) bool {
+		return s.add(0, row)
+	})
+
+	assert.Equal(t, []bool{true, true, false, true, false}, added)
+	assert.Equal(t, []int{1, 3}, s.others[0])
+}
+
+func transpose(lines [][]string) [][]string {
+	if len(lines) == 0 {
+		return lines
+	}
+	out := make([][]string, len(lines[0]))
+	for i := range out {
+		out[i] = make([]string, len(lines))
+		for j := range lines {
+			out[i][j] = lines[j][i]
+		}
+	}
+	return out
+}
+
 func TestGetLineValues(t *testing.T) {
 	file := CSVFile{
 		Header: []string{"col_1", "col_2", "col_3"},
@@ -174,3 +230,67 @@ func TestGetColumnValues(t *testing.T) {
 		})
 	}
 }
+
+func TestIndex(t *testing.T) {
+	file := CSVFile{
+		Header: []string{"id", "name"},
+		Lines: [][]string{
+			{"1", "2", "3", "4"},
+			{"a", "b", "a", "a"},
+		},
+	}
+
+	index, ok := file.Index("name")
+	assert.True(t, ok)
+	assert.Equal(t, map[string][]int{"a": {0, 2, 3}, "b": {1}}, index)
+
+	_, ok = file.Index("missing")
+	assert.False(t, ok)
+}
+
+func TestIndexCached(t *testing.T) {
+	files := map[string]CSVFile{
+		"person": {
+			Name:   "person",
+			Header: []string{"name"},
+			Lines:  [][]string{{"a", "b"}},
+		},
+	}
+
+	first, ok := GetIndex("person", "name", files)
+	assert.True(t, ok)
+
+	// Copies of the file share its cached indexes.
+	file := files["person"]
+	second, _ := file.Index("name")
+	assert.Equal(t, reflect.ValueOf(first).Pointer(), reflect.ValueOf(second).Pointer())
+
+	// Replacing the column rebuilds its index.
+	file.Lines[0] = []string{"c"}
+	third, _ := file.Index("name")
+	assert.Equal(t, map[string][]int{"c": {0}}, third)
+
+	_, ok = GetIndex("missing", "name", files)
+	assert.False(t, ok)
+}
+
+func TestCacheIndexes(t *testing.T) {
+	files := map[string]CSVFile{
+		"person": {
+			Name:   "person",
+			Header: []string{"name"},
+			Lines:  [][]string{{"a", "b"}},
+		},
+	}
+
+	CacheIndexes(files)
+	cached := files["person"]
+
+	// Copies of files share the cache, and files aren't written to.
+	copied := map[string]CSVFile{"person": cached}
+	first, ok := GetIndex("person", "name", copied)
+	assert.True(t, ok)
+	second, _ := GetIndex("person", "name", files)
+	assert.Equal(t, reflect.ValueOf(first).Pointer(), reflect.ValueOf(second).Pointer())
+	assert.Equal(t, cached, files["person"])
+}
@@ -0,0 +1,96 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// DataType is the declared type of a column's values, used to validate
+// generated values and to encode them in typed output formats.
+type DataType string
+
+// Supported data types.
+const (
+	DataTypeInt       DataType = "int"
+	DataTypeFloat     DataType = "float"
+	DataTypeDecimal   DataType = "decimal"
+	DataTypeBool      DataType = "bool"
+	DataTypeDate      DataType = "date"
+	DataTypeTimestamp DataType = "timestamp"
+	DataTypeUUID      DataType = "uuid"
+	DataTypeJSON      DataType = "json"
+	DataTypeString    DataType = "string"
+)
+
+var (
+	decimalPattern = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)$`)
+	uuidPattern    = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// Valid returns true if the data type is one of the supported data types.
+func (dt DataType) Valid() bool {
+	switch dt {
+	case DataTypeInt, DataTypeFloat, DataTypeDecimal, DataTypeBool, DataTypeDate,
+		DataTypeTimestamp, DataTypeUUID, DataTypeJSON, DataTypeString:
+		return true
+	default:
+		return false
+	}
+}
+
+// Numeric returns true if values of the data type are numbers.
+func (dt DataType) Numeric() bool {
+	return dt == DataTypeInt || dt == DataTypeFloat || dt == DataTypeDecimal
+}
+
+// Check returns an error if the value can't be parsed as the data type.
+// Empty values represent nulls and are valid for every data type.
+func (dt DataType) Check(value string) error {
+	if value == "" {
+		return nil
+	}
+
+	var ok bool
+	switch dt {
+	case DataTypeInt:
+		_, err := strconv.ParseInt(value, 10, 64)
+		ok = err == nil
+	case DataTypeFloat:
+		_, err := strconv.ParseFloat(value, 64)
+		ok = err == nil
+	case DataTypeDecimal:
+		ok = decimalPattern.MatchString(value)
+	case DataTypeBool:
+		_, err := strconv.ParseBool(value)
+		ok = err == nil
+	case DataTypeDate, DataTypeTimestamp:
+		_, ok = ParseDate(value, "")
+	case DataTypeUUID:
+		ok = uuidPattern.MatchString(value)
+	case DataTypeJSON:
+		ok = json.Valid([]byte(value))
+	case DataTypeString, "":
+		ok = true
+	default:
+		return fmt.Errorf("%q is not a valid data type", dt)
+	}
+
+	if !ok {
+		return fmt.Errorf("%q is not a valid %s", value, dt)
+	}
+	return nil
+}
+
+// DataTypes returns the declared data types of a table's columns, keyed by
+// column name. Columns without a declared data type are omitted.
+func (t Table) DataTypes() map[string]DataType {
+	types := map[string]DataType{}
+	for _, c := range t.Columns {
+		if c.DataType != "" {
+			types[c.Name] = c.DataType
+		}
+	}
+	return types
+}
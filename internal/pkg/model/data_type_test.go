@@ -0,0 +1,57 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataTypeCheck(t *testing.T) {
+	cases := []struct {
+		dataType DataType
+		valid    []string
+		invalid  []string
+	}{
+		{dataType: DataTypeInt, valid: []string{"1", "-42", "007", ""}, invalid: []string{"1.5", "a", "1e3"}},
+		{dataType: DataTypeFloat, valid: []string{"1", "1.5", "-2.5e3"}, invalid: []string{"a", "1,5"}},
+		{dataType: DataTypeDecimal, valid: []string{"1", "1.50", "-0.5", ".5", "+3."}, invalid: []string{"1e3", "a", "1.2.3"}},
+		{dataType: DataTypeBool, valid: []string{"true", "false", "1", "0", "t", "F"}, invalid: []string{"yes", "2"}},
+		{dataType: DataTypeDate, valid: []string{"2024-01-31", "2024/01/31"}, invalid: []string{"2024-13-01", "tomorrow"}},
+		{dataType: DataTypeTimestamp, valid: []string{"2024-01-31T10:00:00Z", "2024-01-31 10:00:00"}, invalid: []string{"10:00"}},
+		{dataType: DataTypeUUID, valid: []string{"ce9af887-37eb-4e08-9790-4f481b0fa594"}, invalid: []string{"ce9af887", "ce9af887-37eb-4e08-9790-4f481b0fa59z"}},
+		{dataType: DataTypeJSON, valid: []string{`{"a": 1}`, `[1, 2]`, `"a"`}, invalid: []string{`{a: 1}`}},
+		{dataType: DataTypeString, valid: []string{"anything", "1"}},
+	}
+
+	for _, c := range cases {
+		t.Run(string(c.dataType), func(t *testing.T) {
+			assert.True(t, c.dataType.Valid())
+
+			for _, v := range c.valid {
+				assert.NoError(t, c.dataType.Check(v), v)
+			}
+			for _, v := range c.invalid {
+				assert.Error(t, c.dataType.Check(v), v)
+			}
+		})
+	}
+}
+
+func TestDataTypeInvalid(t *testing.T) {
+	dt := DataType("money")
+
+	assert.False(t, dt.Valid())
+	assert.EqualError(t, dt.Check("1"), `"money" is not a valid data type`)
+}
+
+func TestTableDataTypes(t *testing.T) {
+	table := Table{
+		Columns: []Column{
+			{Name: "id", DataType: DataTypeUUID},
+			{Name: "name"},
+			{Name: "age", DataType: DataTypeInt},
+		},
+	}
+
+	assert.Equal(t, map[string]DataType{"id": DataTypeUUID, "age": DataTypeInt}, table.DataTypes())
+}
@@ -0,0 +1,240 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Position is a location in a config file. Lines and columns start at 1 and
+// are 0 if they aren't known.
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// String returns the position as file:line:col, leaving out any parts that
+// aren't known.
+func (p Position) String() string {
+	parts := []string{}
+	if p.File != "" {
+		parts = append(parts, p.File)
+	}
+	if p.Line > 0 {
+		parts = append(parts, strconv.Itoa(p.Line))
+		if p.Column > 0 {
+			parts = append(parts, strconv.Itoa(p.Column))
+		}
+	}
+	return strings.Join(parts, ":")
+}
+
+func nodePosition(node *yaml.Node) Position {
+	if node == nil {
+		return Position{}
+	}
+	return Position{Line: node.Line, Column: node.Column}
+}
+
+// ConfigError is a problem found at a position in a config file.
+type ConfigError struct {
+	Position Position
+	Err      error
+}
+
+func (e ConfigError) Error() string {
+	if p := e.Position.String(); p != "" {
+		return p + ": " + e.Err.Error()
+	}
+	return e.Err.Error()
+}
+
+func (e ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// Wrap returns err as a ConfigError at the position. If err already has a
+// position, that position is moved to the front of the error's message
+// instead, as it's the more precise of the two. err is returned as it is if
+// it's nil, or if it has no position and the position isn't known.
+func (p Position) Wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var inner ConfigError
+	if !errors.As(err, &inner) {
+		if p == (Position{}) {
+			return err
+		}
+		return ConfigError{Position: p, Err: err}
+	}
+
+	if _, ok := err.(ConfigError); ok {
+		return err
+	}
+
+	// Remove the inner position from the message, so that it's only
+	// reported once.
+	msg := strings.Replace(err.Error(), inner.Error(), inner.Err.Error(), 1)
+	return ConfigError{Position: inner.Position, Err: positionedError{msg: msg, err: err}}
+}
+
+// positionedError is an error whose position has been moved to the front of
+// its message.
+type positionedError struct {
+	msg string
+	err error
+}
+
+func (e positionedError) Error() string {
+	return e.msg
+}
+
+func (e positionedError) Unwrap() error {
+	return e.err
+}
+
+// Errors flattens errors joined with errors.Join into a list.
+func Errors(err error) []error {
+	if err == nil {
+		return nil
+	}
+
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+
+	var errs []error
+	for _, e := range joined.Unwrap() {
+		errs = append(errs, Errors(e)...)
+	}
+	return errs
+}
+
+var rawMessageType = reflect.TypeOf(RawMessage{})
+
+// unknownFields returns an error for each key of a mapping in node that
+// doesn't match a field of the struct it's decoded into. Processors and
+// sources (RawMessages) aren't checked, as their types depend on the type of
+// the column or input that holds them.
+func unknownFields(file string, node *yaml.Node, t reflect.Type) []error {
+	for node != nil && (node.Kind == yaml.DocumentNode || node.Kind == yaml.AliasNode) {
+		if node.Kind == yaml.AliasNode {
+			node = node.Alias
+		} else if len(node.Content) > 0 {
+			node = node.Content[0]
+		} else {
+			return nil
+		}
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if node == nil || t == rawMessageType {
+		return nil
+	}
+
+	var errs []error
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			field, ok := fields[key.Value]
+			if !ok {
+				pos := nodePosition(key)
+				pos.File = file
+				errs = append(errs, ConfigError{Position: pos, Err: fmt.Errorf("unknown field %q", key.Value)})
+				continue
+			}
+			errs = append(errs, unknownFields(file, value, field)...)
+		}
+
+	case reflect.Slice, reflect.Array:
+		if node.Kind != yaml.SequenceNode {
+			return nil
+		}
+		for _, item := range node.Content {
+			errs = append(errs, unknownFields(file, item, t.Elem())...)
+		}
+
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		for i := 1; i < len(node.Content); i += 2 {
+			errs = append(errs, unknownFields(file, node.Content[i], t.Elem())...)
+		}
+	}
+
+	return errs
+}
+
+// yamlFields returns the types of a struct's fields, by the keys they're
+// decoded from.
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if strings.Contains(opts, "inline") && f.Type.Kind() == reflect.Struct {
+			for k, v := range yamlFields(f.Type) {
+				fields[k] = v
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+var (
+	typeErrorLine   = regexp.MustCompile(`^line (\d+): (.*)$`)
+	syntaxErrorLine = regexp.MustCompile(`yaml: line (\d+): (.*)$`)
+)
+
+// positionErrors converts the errors yaml returns for documents that can't
+// be parsed, or values that can't be decoded, into ConfigErrors.
+func positionErrors(file string, err error) []error {
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		pos := Position{File: file}
+		if m := syntaxErrorLine.FindStringSubmatch(err.Error()); m != nil {
+			pos.Line, _ = strconv.Atoi(m[1])
+			err = errors.New(m[2])
+		}
+		return []error{ConfigError{Position: pos, Err: err}}
+	}
+
+	errs := make([]error, len(typeErr.Errors))
+	for i, msg := range typeErr.Errors {
+		pos := Position{File: file}
+		if m := typeErrorLine.FindStringSubmatch(msg); m != nil {
+			pos.Line, _ = strconv.Atoi(m[1])
+			msg = m[2]
+		}
+		errs[i] = ConfigError{Position: pos, Err: errors.New(msg)}
+	}
+	return errs
+}
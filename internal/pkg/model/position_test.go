@@ -0,0 +1,268 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestPositionString(t *testing.T) {
+	cases := []struct {
+		name     string
+		position Position
+		exp      string
+	}{
+		{name: "full", position: Position{File: "config.yaml", Line: 3, Column: 5}, exp: "config.yaml:3:5"},
+		{name: "without column", position: Position{File: "config.yaml", Line: 3}, exp: "config.yaml:3"},
+		{name: "without file", position: Position{Line: 3, Column: 5}, exp: "3:5"},
+		{name: "file only", position: Position{File: "config.yaml"}, exp: "config.yaml"},
+		{name: "unknown", exp: ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.exp, c.position.String())
+		})
+	}
+}
+
+func TestDecodeStrict(t *testing.T) {
+	type inner struct {
+		Name string `yaml:"name"`
+	}
+	type processor struct {
+		Low    int     `yaml:"low"`
+		Values []inner `yaml:"values"`
+		Other  string
+	}
+
+	y := `
+p:
+  low: 1
+  hihg: 2
+  other: x
+  values:
+    - name: a
+    - nmae: b
+`
+
+	var doc struct {
+		P RawMessage `yaml:"p"`
+	}
+	if err := yaml.Unmarshal([]byte(y), &doc); err != nil {
+		t.Fatalf("error decoding yaml: %v", err)
+	}
+
+	var p processor
+	err := doc.P.DecodeStrict(&p)
+
+	assert.Equal(t, []string{
+		`4:3: unknown field "hihg"`,
+		`8:7: unknown field "nmae"`,
+	}, lo.Map(Errors(err), func(err error, _ int) string { return err.Error() }))
+	assert.Equal(t, processor{Low: 1, Other: "x", Values: []inner{{Name: "a"}, {}}}, p)
+
+	var bad struct {
+		Low int `yaml:"low"`
+	}
+	err = doc.P.DecodeStrict(&bad)
+	assert.Contains(t, lo.Map(Errors(err), func(err error, _ int) string { return err.Error() }), `5:3: unknown field "other"`)
+}
+
+func TestCheckConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		filename := path.Join(dir, name)
+		if err := os.WriteFile(filename, []byte(strings.TrimPrefix(content, "\n")), 0644); err != nil {
+			t.Fatalf("error writing file: %v", err)
+		}
+		return filename
+	}
+
+	write("base.yaml", `
+extends: [config.yaml]
+tables:
+  - name: person
+    cuont: 10
+`)
+	config := write("config.yaml", `
+extends: [base.yaml]
+inputs:
+  - name: market
+    type: csv
+    suppress: true
+tables:
+  - name: person
+    columns:
+      - name: id
+        type: inc
+        proccessor:
+          start: 1
+    output:
+      fromat: json
+`)
+
+	act := lo.Map(Errors(CheckConfigFile(config)), func(err error, _ int) string {
+		return strings.TrimPrefix(err.Error(), dir+"/")
+	})
+
+	assert.Equal(t, []string{
+		`config.yaml:5:5: unknown field "suppress"`,
+		`config.yaml:11:9: unknown field "proccessor"`,
+		`config.yaml:14:7: unknown field "fromat"`,
+		`base.yaml:4:5: unknown field "cuont"`,
+	}, act)
+}
+
+func TestLoadConfigFilePositions(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(path.Join(dir, "base.yaml"), []byte(`tables:
+  - name: pet
+    columns:
+      - name: id
+        type: inc
+        processor:
+          start: 1
+`), 0644); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+
+	config := path.Join(dir, "config.yaml")
+	if err := os.WriteFile(config, []byte(`extends: [base.yaml]
+tables:
+  - name: person
+    columns:
+      - name: id
+        type: inc
+        processor:
+          start: 1
+`), 0644); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+
+	c, err := LoadConfigFile(config)
+	if err != nil {
+		t.Fatalf("error loading config: %v", err)
+	}
+
+	assert.Equal(t, Position{File: path.Join(dir, "base.yaml"), Line: 2, Column: 5}, c.Tables[0].Position)
+	assert.Equal(t, Position{File: path.Join(dir, "base.yaml"), Line: 4, Column: 9}, c.Tables[0].Columns[0].Position)
+	assert.Equal(t, Position{File: config, Line: 3, Column: 5}, c.Tables[1].Position)
+	assert.Equal(t, Position{File: config, Line: 8, Column: 11}, c.Tables[1].Columns[0].Generator.Position)
+}
+
+func TestPositionWrap(t *testing.T) {
+	pos := Position{File: "config.yaml", Line: 3, Column: 5}
+	inner := Position{File: "base.yaml", Line: 9, Column: 11}
+
+	cases := []struct {
+		name     string
+		position Position
+		err      error
+		exp      string
+		expPos   Position
+	}{
+		{
+			name:     "error without a position",
+			position: pos,
+			err:      errors.New("boom"),
+			exp:      "config.yaml:3:5: boom",
+			expPos:   pos,
+		},
+		{
+			name: "unknown position",
+			err:  errors.New("boom"),
+			exp:  "boom",
+		},
+		{
+			name:     "error with a position",
+			position: pos,
+			err:      inner.Wrap(errors.New("boom")),
+			exp:      "base.yaml:9:11: boom",
+			expPos:   inner,
+		},
+		{
+			name:     "wrapped error with a position",
+			position: pos,
+			err:      fmt.Errorf("generating %q: %w", "person", inner.Wrap(errors.New("boom"))),
+			exp:      `base.yaml:9:11: generating "person": boom`,
+			expPos:   inner,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.position.Wrap(c.err)
+			assert.EqualError(t, err, c.exp)
+			assert.ErrorIs(t, err, c.err)
+
+			var configErr ConfigError
+			if errors.As(err, &configErr) {
+				assert.Equal(t, c.expPos, configErr.Position)
+			} else {
+				assert.Equal(t, Position{}, c.expPos)
+			}
+		})
+	}
+
+	assert.Nil(t, pos.Wrap(nil))
+}
+
+func TestLoadConfigFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		filename := path.Join(dir, name)
+		if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatalf("error writing file: %v", err)
+		}
+		return filename
+	}
+
+	cases := []struct {
+		name     string
+		filename string
+		exp      string
+	}{
+		{
+			name:     "value of the wrong type",
+			filename: write("type.yaml", "tables:\n  - name: person\n    count: [1]\n"),
+			exp:      "type.yaml:3: cannot unmarshal !!seq into int",
+		},
+		{
+			name:     "invalid yaml",
+			filename: write("syntax.yaml", "tables:\n  - name: person\n    count: 1\n   seed: 1\n"),
+			exp:      "syntax.yaml:",
+		},
+		{
+			name:     "error in an extended file",
+			filename: write("extends.yaml", "extends: [type.yaml]\n"),
+			exp:      "type.yaml:3: cannot unmarshal !!seq into int",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := LoadConfigFile(c.filename)
+
+			var configErr ConfigError
+			if assert.ErrorAs(t, err, &configErr) {
+				assert.Equal(t, path.Join(dir, strings.Split(c.exp, ":")[0]), configErr.Position.File)
+				assert.Contains(t, err.Error(), strings.TrimPrefix(c.exp, strings.Split(c.exp, ":")[0]))
+			}
+		})
+	}
+}
+
+func TestMergeConfigFile(t *testing.T) {
+	merged := MergeConfig(Config{}, Config{File: "a.yaml"})
+	merged = MergeConfig(merged, Config{File: "b.yaml"})
+
+	assert.Equal(t, "a.yaml", merged.File)
+}
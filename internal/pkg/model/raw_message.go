@@ -2,6 +2,9 @@ package model
 
 import (
 	"bytes"
+	"errors"
+	"reflect"
+	"slices"
 	"testing"
 
 	"gopkg.in/yaml.v3"
@@ -10,13 +13,73 @@ import (
 // RawMessage does what json.RawMessage does but for YAML.
 type RawMessage struct {
 	UnmarshalFunc func(interface{}) error
+
+	// Position is where the message starts in its config file.
+	Position Position
+
+	node *yaml.Node
 }
 
-func (msg *RawMessage) UnmarshalYAML(unmarshal func(interface{}) error) error {
-	msg.UnmarshalFunc = unmarshal
+// UnmarshalYAML keeps the message's node, which is decoded with a new
+// decoder each time UnmarshalFunc is called, so that messages can be decoded
+// from multiple goroutines.
+func (msg *RawMessage) UnmarshalYAML(value *yaml.Node) error {
+	node := *value
+	msg.node = &node
+	msg.Position = nodePosition(value)
+	msg.UnmarshalFunc = func(v interface{}) error {
+		return node.Decode(v)
+	}
 	return nil
 }
 
+// DecodeStrict decodes the message into v, as UnmarshalFunc does, but also
+// reports the message's fields that v doesn't have. Each problem is returned
+// as a ConfigError, joined with errors.Join.
+func (msg RawMessage) DecodeStrict(v interface{}) error {
+	if msg.node == nil {
+		return nil
+	}
+
+	var errs []error
+	if err := msg.node.Decode(v); err != nil {
+		errs = positionErrors(msg.Position.File, err)
+	}
+	errs = append(errs, unknownFields(msg.Position.File, msg.node, reflect.TypeOf(v))...)
+	return errors.Join(errs...)
+}
+
+// Patch returns a message with the fields of msg and other, taking the
+// values of fields that both have from other. If either message isn't a
+// mapping, other is returned.
+func (msg RawMessage) Patch(other RawMessage) RawMessage {
+	if msg.node == nil || other.node == nil || msg.node.Kind != yaml.MappingNode || other.node.Kind != yaml.MappingNode {
+		return other
+	}
+
+	node := *msg.node
+	node.Content = slices.Clone(msg.node.Content)
+	for i := 0; i+1 < len(other.node.Content); i += 2 {
+		key, value := other.node.Content[i], other.node.Content[i+1]
+
+		j := 0
+		for ; j+1 < len(node.Content); j += 2 {
+			if node.Content[j].Value == key.Value {
+				node.Content[j+1] = value
+				break
+			}
+		}
+		if j+1 >= len(node.Content) {
+			node.Content = append(node.Content, key, value)
+		}
+	}
+
+	var patched RawMessage
+	patched.UnmarshalYAML(&node)
+	patched.Position = other.Position
+	return patched
+}
+
 // ToRawMessage converts an object into a model.RawMessage for testing purposes.
 func ToRawMessage(t *testing.T, v any) RawMessage {
 	buf := &bytes.Buffer{}
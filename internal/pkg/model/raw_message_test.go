@@ -28,3 +28,39 @@ func TestRawMessageUnmarshal(t *testing.T) {
 
 	assert.Equal(t, "hello raw message", s)
 }
+
+func TestRawMessagePatch(t *testing.T) {
+	type test struct {
+		A RawMessage `yaml:"a"`
+		B RawMessage `yaml:"b"`
+	}
+
+	y := `
+a:
+  file_name: a.csv
+  delimiter: ";"
+b:
+  file_name: b.csv
+  quote: "'"`
+
+	var tst test
+	if err := yaml.NewDecoder(strings.NewReader(y)).Decode(&tst); err != nil {
+		t.Fatalf("error decoding yaml: %v", err)
+	}
+
+	patched := tst.A.Patch(tst.B)
+	assert.Equal(t, tst.B.Position, patched.Position)
+
+	var m map[string]string
+	if err := patched.UnmarshalFunc(&m); err != nil {
+		t.Fatalf("error decoding patched yaml: %v", err)
+	}
+	assert.Equal(t, map[string]string{"file_name": "b.csv", "delimiter": ";", "quote": "'"}, m)
+
+	// The original is unchanged.
+	var a map[string]string
+	if err := tst.A.UnmarshalFunc(&a); err != nil {
+		t.Fatalf("error decoding yaml: %v", err)
+	}
+	assert.Equal(t, map[string]string{"file_name": "a.csv", "delimiter": ";"}, a)
+}
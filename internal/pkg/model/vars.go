@@ -0,0 +1,170 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// VarEnvPrefix is the prefix of environment variables that override config
+// vars (e.g. DG_VAR_rows=1000 overrides the rows var).
+const VarEnvPrefix = "DG_VAR_"
+
+var varReference = regexp.MustCompile(`\$\{var\.([^}]+)\}`)
+
+// ParseVars parses name=value assignments into vars. Values are parsed as
+// YAML scalars, so that numbers and booleans keep their types. Later
+// assignments to a name override earlier ones.
+func ParseVars(assignments []string) (map[string]any, error) {
+	vars := map[string]any{}
+	for _, assignment := range assignments {
+		name, value, ok := strings.Cut(assignment, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid var %q (expected name=value)", assignment)
+		}
+
+		var v any
+		if err := yaml.Unmarshal([]byte(value), &v); err != nil || !isScalar(v) {
+			v = value
+		}
+		vars[name] = v
+	}
+	return vars, nil
+}
+
+func isScalar(v any) bool {
+	switch v.(type) {
+	case map[string]any, []any:
+		return false
+	}
+	return true
+}
+
+// EnvVars returns the var assignments (name=value) of environment variables
+// that start with VarEnvPrefix.
+func EnvVars(environ []string) []string {
+	var assignments []string
+	for _, env := range environ {
+		if assignment, ok := strings.CutPrefix(env, VarEnvPrefix); ok {
+			assignments = append(assignments, assignment)
+		}
+	}
+	return assignments
+}
+
+// interpolateConfig replaces references to vars (${var.name}) in the counts
+// of a config's tables, the processors of its columns and the sources of its
+// inputs.
+func interpolateConfig(file string, node *yaml.Node, vars map[string]any) error {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	var errs []error
+	for _, table := range sequence(mappingValue(node, "tables")) {
+		errs = append(errs, interpolate(file, mappingValue(table, "count"), vars)...)
+		for _, column := range sequence(mappingValue(table, "columns")) {
+			errs = append(errs, interpolate(file, mappingValue(column, "processor"), vars)...)
+		}
+	}
+	for _, input := range sequence(mappingValue(node, "inputs")) {
+		errs = append(errs, interpolate(file, mappingValue(input, "source"), vars)...)
+	}
+
+	return errors.Join(errs...)
+}
+
+// interpolate replaces references to vars in the scalars of node. A scalar
+// that's just a reference takes the type of the var, so that numbers can be
+// decoded into numeric fields.
+func interpolate(file string, node *yaml.Node, vars map[string]any) []error {
+	if node == nil {
+		return nil
+	}
+
+	if node.Kind != yaml.ScalarNode {
+		var errs []error
+		for _, child := range node.Content {
+			errs = append(errs, interpolate(file, child, vars)...)
+		}
+		return errs
+	}
+
+	var errs []error
+	value := varReference.ReplaceAllStringFunc(node.Value, func(ref string) string {
+		name := varReference.FindStringSubmatch(ref)[1]
+		v, ok := vars[name]
+		if !ok {
+			errs = append(errs, ConfigError{
+				Position: Position{File: file, Line: node.Line, Column: node.Column},
+				Err:      fmt.Errorf("unknown var %q", name),
+			})
+			return ref
+		}
+		if v == nil {
+			return ""
+		}
+		return fmt.Sprint(v)
+	})
+	if len(errs) > 0 || value == node.Value {
+		return errs
+	}
+
+	if m := varReference.FindStringSubmatch(node.Value); m != nil && m[0] == node.Value {
+		node.Tag = varTag(vars[m[1]])
+		node.Style = 0
+	}
+	node.Value = value
+	return nil
+}
+
+// varTag returns the YAML tag of a var's value.
+func varTag(v any) string {
+	switch v.(type) {
+	case int, int64, uint64:
+		return "!!int"
+	case float64:
+		return "!!float"
+	case bool:
+		return "!!bool"
+	case nil:
+		return "!!null"
+	default:
+		return "!!str"
+	}
+}
+
+// mappingValue returns the value of a key in a mapping node, or nil if node
+// isn't a mapping or doesn't have the key.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if i := mappingIndex(node, key); i != -1 {
+		return node.Content[i+1]
+	}
+	return nil
+}
+
+// mappingIndex returns the index of a key in the content of a mapping node,
+// or -1 if node isn't a mapping or doesn't have the key.
+func mappingIndex(node *yaml.Node, key string) int {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return -1
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// sequence returns the items of a sequence node, or nil if node isn't a
+// sequence.
+func sequence(node *yaml.Node) []*yaml.Node {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	return node.Content
+}
@@ -0,0 +1,271 @@
+package model
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVars(t *testing.T) {
+	cases := []struct {
+		name        string
+		assignments []string
+		exp         map[string]any
+		expErr      string
+	}{
+		{
+			name:        "types",
+			assignments: []string{"rows=1000", "ratio=0.5", "enabled=true", "region=eu", "from=", "expr=a=b"},
+			exp: map[string]any{
+				"rows":    1000,
+				"ratio":   0.5,
+				"enabled": true,
+				"region":  "eu",
+				"from":    nil,
+				"expr":    "a=b",
+			},
+		},
+		{
+			name:        "later assignments override earlier ones",
+			assignments: []string{"rows=10", "rows=20"},
+			exp:         map[string]any{"rows": 20},
+		},
+		{
+			name:        "values that aren't scalars are strings",
+			assignments: []string{"list=[1, 2]"},
+			exp:         map[string]any{"list": "[1, 2]"},
+		},
+		{
+			name:        "missing value",
+			assignments: []string{"rows"},
+			expErr:      `invalid var "rows" (expected name=value)`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			act, err := ParseVars(c.assignments)
+			if c.expErr != "" {
+				assert.EqualError(t, err, c.expErr)
+				return
+			}
+			if err != nil {
+				t.Fatalf("error parsing vars: %v", err)
+			}
+			assert.Equal(t, c.exp, act)
+		})
+	}
+}
+
+func TestEnvVars(t *testing.T) {
+	act := EnvVars([]string{"HOME=/root", "DG_VAR_rows=10", "DG_VARS=1", "DG_VAR_region=eu"})
+	assert.Equal(t, []string{"rows=10", "region=eu"}, act)
+}
+
+func TestLoadConfigVars(t *testing.T) {
+	y := `
+vars:
+  rows: 10
+  min_age: 18
+  region: eu
+inputs:
+  - name: market
+    type: csv
+    source:
+      file_name: markets_${var.region}.csv
+tables:
+  - name: person
+    count: ${var.rows}
+    columns:
+      - name: age
+        type: rand
+        processor:
+          type: int
+          low: ${var.min_age}
+          high: 65
+      - name: region
+        type: set
+        processor:
+          values: ["${var.region}", "us"]
+      - name: email
+        type: gen
+        processor:
+          value: ${email}`
+
+	c, err := LoadConfig(strings.NewReader(y), ".")
+	if err != nil {
+		t.Fatalf("error loading config: %v", err)
+	}
+
+	assert.Equal(t, 10, c.Tables[0].Count)
+	assert.Equal(t, map[string]any{"rows": 10, "min_age": 18, "region": "eu"}, c.Vars)
+
+	var source SourceCSV
+	if err = c.Inputs[0].Source.UnmarshalFunc(&source); err != nil {
+		t.Fatalf("error decoding source: %v", err)
+	}
+	assert.Equal(t, "markets_eu.csv", source.FileName)
+
+	processors := lo.Map(c.Tables[0].Columns, func(c Column, _ int) map[string]any {
+		var m map[string]any
+		if err := c.Generator.UnmarshalFunc(&m); err != nil {
+			t.Fatalf("error decoding processor: %v", err)
+		}
+		return m
+	})
+	assert.Equal(t, 18, processors[0]["low"])
+	assert.Equal(t, []any{"eu", "us"}, processors[1]["values"])
+
+	// References that aren't to vars are left for processors to handle.
+	assert.Equal(t, "${email}", processors[2]["value"])
+}
+
+func TestLoadConfigUnknownVar(t *testing.T) {
+	y := `
+tables:
+  - name: person
+    count: ${var.rows}
+    columns:
+      - name: age
+        type: rand
+        processor:
+          low: ${var.low}`
+
+	_, err := LoadConfig(strings.NewReader(y), ".")
+	assert.Equal(t, []string{
+		`4:12: unknown var "rows"`,
+		`9:16: unknown var "low"`,
+	}, lo.Map(Errors(err), func(err error, _ int) string { return err.Error() }))
+}
+
+func TestLoadConfigFilesVars(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		filename := path.Join(dir, name)
+		if err := os.WriteFile(filename, []byte(strings.TrimPrefix(content, "\n")), 0644); err != nil {
+			t.Fatalf("error writing file: %v", err)
+		}
+		return filename
+	}
+
+	write("base.yaml", `
+vars:
+  rows: 10
+  pets: 2
+tables:
+  - name: person
+    count: ${var.rows}
+`)
+	config := write("config.yaml", `
+extends: [base.yaml]
+vars:
+  pets: 5
+tables:
+  - name: pet
+    count: ${var.pets}
+`)
+	extra := write("extra.yaml", `
+tables:
+  - name: toy
+    count: ${var.rows}
+`)
+
+	cases := []struct {
+		name      string
+		overrides map[string]any
+		expCounts map[string]int
+	}{
+		{
+			name:      "declared vars",
+			expCounts: map[string]int{"person": 10, "pet": 5, "toy": 10},
+		},
+		{
+			name:      "overridden vars",
+			overrides: map[string]any{"rows": 100, "pets": 50},
+			expCounts: map[string]int{"person": 100, "pet": 50, "toy": 100},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			act, err := LoadConfigFiles([]string{config, extra}, c.overrides)
+			if err != nil {
+				t.Fatalf("error loading configs: %v", err)
+			}
+			assert.Equal(t, c.expCounts, counts(act))
+			assert.Equal(t, c.expCounts["person"], act.Vars["rows"])
+		})
+	}
+}
+
+func TestLoadConfigFilesVarsOrder(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		filename := path.Join(dir, name)
+		if err := os.WriteFile(filename, []byte(strings.TrimPrefix(content, "\n")), 0644); err != nil {
+			t.Fatalf("error writing file: %v", err)
+		}
+		return filename
+	}
+
+	base := write("base.yaml", `
+vars:
+  rows: 5
+tables:
+  - name: person
+    count: ${var.rows}
+`)
+	small := write("small.yaml", `
+extends: [base.yaml]
+vars:
+  rows: 2
+`)
+	pets := write("pets.yaml", `
+vars:
+  rows: 3
+tables:
+  - name: pet
+    count: ${var.rows}
+`)
+
+	cases := []struct {
+		name      string
+		files     []string
+		expCounts map[string]int
+		expRows   int
+	}{
+		{
+			name:      "extending file",
+			files:     []string{small},
+			expCounts: map[string]int{"person": 2},
+			expRows:   2,
+		},
+		{
+			name:      "later file",
+			files:     []string{base, pets},
+			expCounts: map[string]int{"person": 3, "pet": 3},
+			expRows:   3,
+		},
+		{
+			name:      "later file and extending file",
+			files:     []string{pets, small},
+			expCounts: map[string]int{"person": 2, "pet": 2},
+			expRows:   2,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			act, err := LoadConfigFiles(c.files, nil)
+			if err != nil {
+				t.Fatalf("error loading configs: %v", err)
+			}
+			assert.Equal(t, c.expCounts, counts(act))
+			assert.Equal(t, c.expRows, act.Vars["rows"])
+		})
+	}
+}
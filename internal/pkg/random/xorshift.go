@@ -1,9 +1,17 @@
 package random
 
-import "time"
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
 
 var (
-	r = newSplitMix64(time.Now().UnixNano())
+	mu   sync.Mutex
+	seed = time.Now().UnixNano()
+	r    = newSplitMix64(seed)
 )
 
 type splitMix64 struct {
@@ -16,13 +24,57 @@ func newSplitMix64(seed int64) *splitMix64 {
 	}
 }
 
+// Seed resets the root source that every generator's random source is
+// derived from, so that the same seed always yields the same data.
+func Seed(s int64) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	seed = s
+	r = newSplitMix64(seed)
+}
+
+// New returns a random source seeded from the root source. Sources created
+// in the same order after the same call to Seed produce the same values.
+func New() *rand.Rand {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return rand.New(newSplitMix64(int64(r.Uint64())))
+}
+
+// Derive returns a random source seeded from the root seed and the given
+// keys (e.g. a table and column name). Unlike New, the source doesn't depend
+// on how many sources were created before it, so adding or reordering
+// columns in a config doesn't change the values of other columns.
+func Derive(keys ...string) *rand.Rand {
+	mu.Lock()
+	s := seed
+	mu.Unlock()
+
+	h := fnv.New64a()
+	binary.Write(h, binary.LittleEndian, s)
+	for _, key := range keys {
+		h.Write([]byte(key))
+
+		// Separate keys, so that ("ab", "c") and ("a", "bc") differ.
+		h.Write([]byte{0})
+	}
+
+	return rand.New(newSplitMix64(int64(h.Sum64())))
+}
+
 // Intn returns a non-negative pseudo-random int.
 func Intn(n int) int {
-	return int(r.uint64()&(1<<63-1)) % n
+	mu.Lock()
+	defer mu.Unlock()
 
+	return int(r.Uint64()&(1<<63-1)) % n
 }
 
-func (x *splitMix64) uint64() uint64 {
+// Uint64 returns the next pseudo-random number in the sequence, satisfying
+// rand.Source.
+func (x *splitMix64) Uint64() uint64 {
 	x.s = x.s + uint64(0x9E3779B97F4A7C15)
 	z := x.s
 	z = (z ^ (z >> 30)) * uint64(0xBF58476D1CE4E5B9)
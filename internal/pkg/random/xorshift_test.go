@@ -0,0 +1,63 @@
+package random
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeed(t *testing.T) {
+	draw := func() []int {
+		var values []int
+		for i := 0; i < 3; i++ {
+			r := New()
+			values = append(values, r.IntN(1000000), r.IntN(1000000))
+		}
+		return values
+	}
+
+	Seed(42)
+	first := draw()
+
+	Seed(42)
+	second := draw()
+
+	Seed(43)
+	third := draw()
+
+	assert.Equal(t, first, second)
+	assert.NotEqual(t, first, third)
+}
+
+func TestIntn(t *testing.T) {
+	Seed(1)
+	for i := 0; i < 100; i++ {
+		v := Intn(10)
+		assert.GreaterOrEqual(t, v, 0)
+		assert.Less(t, v, 10)
+	}
+}
+
+func TestDerive(t *testing.T) {
+	draw := func(keys ...string) []int {
+		r := Derive(keys...)
+		return []int{r.IntN(1000000), r.IntN(1000000), r.IntN(1000000)}
+	}
+
+	Seed(42)
+	person := draw("person", "name")
+	pet := draw("pet", "name")
+
+	// Creating other sources in between doesn't affect derived sources.
+	Seed(42)
+	New()
+	draw("person", "age")
+	assert.Equal(t, person, draw("person", "name"))
+	assert.Equal(t, pet, draw("pet", "name"))
+
+	assert.NotEqual(t, person, pet)
+	assert.NotEqual(t, draw("ab", "c"), draw("a", "bc"))
+
+	Seed(43)
+	assert.NotEqual(t, person, draw("person", "name"))
+}
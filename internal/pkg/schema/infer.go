@@ -0,0 +1,147 @@
+package schema
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/codingconcepts/dg/internal/pkg/generator"
+	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/samber/lo"
+)
+
+// valueTypes are the types that literal values are checked against, from the
+// most to the least specific.
+var valueTypes = []model.DataType{
+	model.DataTypeInt,
+	model.DataTypeDecimal,
+	model.DataTypeBool,
+	model.DataTypeUUID,
+	model.DataTypeDate,
+	model.DataTypeTimestamp,
+}
+
+// inferType returns the data type (and length, if bounded) of the values that
+// a column's processor generates. Processors whose output can't be known
+// without generating it are treated as strings.
+func inferType(c model.Column) (model.DataType, int, error) {
+	if c.Generator.UnmarshalFunc == nil {
+		return model.DataTypeString, 0, nil
+	}
+
+	switch c.Type {
+	case "inc":
+		var g generator.IncGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return "", 0, fmt.Errorf("parsing inc process: %w", err)
+		}
+		if g.Format == "" {
+			return model.DataTypeInt, 0, nil
+		}
+		return valuesType(fmt.Sprintf(g.Format, 1)), 0, nil
+
+	case "range":
+		var g generator.RangeGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return "", 0, fmt.Errorf("parsing range process: %w", err)
+		}
+		switch g.Type {
+		case "int":
+			return model.DataTypeInt, 0, nil
+		case "date":
+			return dateType(g.Format), 0, nil
+		}
+
+	case "rand":
+		var g generator.RandGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return "", 0, fmt.Errorf("parsing rand process: %w", err)
+		}
+		switch g.Type {
+		case "int":
+			if g.Format == "" {
+				return model.DataTypeInt, 0, nil
+			}
+			return valuesType(fmt.Sprintf(g.Format, 1)), 0, nil
+		case "float64":
+			if g.Format == "" {
+				return model.DataTypeFloat, 0, nil
+			}
+			return valuesType(fmt.Sprintf(g.Format, 1.5)), 0, nil
+		case "date":
+			return dateType(g.Format), 0, nil
+		}
+
+	case "rel_date", "relative_date":
+		var g generator.RelDateGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return "", 0, fmt.Errorf("parsing rel_date process: %w", err)
+		}
+		if g.Format == "" {
+			return model.DataTypeDate, 0, nil
+		}
+		return dateType(g.Format), 0, nil
+
+	case "cuid2":
+		var g generator.Cuid2Generator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return "", 0, fmt.Errorf("parsing cuid2 process: %w", err)
+		}
+		return model.DataTypeString, g.Length, nil
+
+	case "gen":
+		var g generator.GenGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return "", 0, fmt.Errorf("parsing gen process: %w", err)
+		}
+		if g.Value == "${uuid}" {
+			return model.DataTypeUUID, 0, nil
+		}
+
+	case "set":
+		var g generator.SetGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return "", 0, fmt.Errorf("parsing set process: %w", err)
+		}
+		return valuesType(g.Values...), 0, nil
+
+	case "const":
+		var g generator.ConstGenerator
+		if err := c.Generator.UnmarshalFunc(&g); err != nil {
+			return "", 0, fmt.Errorf("parsing const process: %w", err)
+		}
+		return valuesType(g.Values...), 0, nil
+	}
+
+	return model.DataTypeString, 0, nil
+}
+
+// valuesType returns the most specific type that every non-empty value is
+// valid for.
+func valuesType(values ...string) model.DataType {
+	values = lo.Compact(values)
+	if len(values) == 0 {
+		return model.DataTypeString
+	}
+
+	for _, dt := range valueTypes {
+		if lo.EveryBy(values, func(v string) bool { return dt.Check(v) == nil }) {
+			return dt
+		}
+	}
+	return model.DataTypeString
+}
+
+// dateType returns whether a date layout includes the time of day.
+func dateType(layout string) model.DataType {
+	if layout == "" {
+		return model.DataTypeDate
+	}
+
+	day := time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC)
+	later := day.Add(13*time.Hour + 4*time.Minute + 5*time.Second)
+
+	if day.Format(layout) == later.Format(layout) {
+		return model.DataTypeDate
+	}
+	return model.DataTypeTimestamp
+}
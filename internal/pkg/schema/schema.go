@@ -0,0 +1,179 @@
+package schema
+
+import (
+	"fmt"
+
+	"github.com/codingconcepts/dg/internal/pkg/generator"
+	"github.com/codingconcepts/dg/internal/pkg/graph"
+	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/samber/lo"
+)
+
+// Table describes the structure of a database table.
+type Table struct {
+	Name        string
+	Columns     []Column
+	PrimaryKey  []string
+	ForeignKeys []ForeignKey
+}
+
+// Column describes a database column. A Length of zero means the column's
+// length is unbounded.
+type Column struct {
+	Name   string
+	Type   model.DataType
+	Length int
+}
+
+// ForeignKey describes a constraint between the columns of one table and the
+// columns of another.
+type ForeignKey struct {
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+}
+
+// FromConfig returns the tables that a config writes, ordered so that tables
+// come after the tables they reference.
+//
+// Column types are taken from a column's data_type or inferred from its
+// processor, and primary keys from columns with primary_key set (or a column
+// named "id" if there are none). Foreign keys are derived from ref, each, fk,
+// match and pick columns that reference another table's primary key.
+func FromConfig(c model.Config) ([]Table, error) {
+	configTables := lo.KeyBy(c.Tables, func(t model.Table) string {
+		return t.Name
+	})
+
+	r := resolver{tables: configTables, resolved: map[generator.Reference]Column{}}
+
+	var tables []Table
+	for _, t := range c.Tables {
+		if t.Suppress {
+			continue
+		}
+
+		table := Table{Name: t.Name, PrimaryKey: PrimaryKey(t)}
+		for _, col := range t.Columns {
+			if col.Suppress {
+				continue
+			}
+
+			column, err := r.resolve(t.Name, col.Name, nil)
+			if err != nil {
+				return nil, fmt.Errorf("inferring type of %s.%s: %w", t.Name, col.Name, err)
+			}
+			table.Columns = append(table.Columns, column)
+
+			ref, ok, err := generator.ColumnReference(col)
+			if err != nil {
+				return nil, fmt.Errorf("finding reference of %s.%s: %w", t.Name, col.Name, err)
+			}
+			if !ok {
+				continue
+			}
+
+			refTable, ok := configTables[ref.Table]
+			if !ok || refTable.Suppress {
+				continue
+			}
+			if pk := PrimaryKey(refTable); len(pk) != 1 || pk[0] != ref.Column {
+				continue
+			}
+
+			table.ForeignKeys = append(table.ForeignKeys, ForeignKey{
+				Columns:    []string{col.Name},
+				RefTable:   ref.Table,
+				RefColumns: []string{ref.Column},
+			})
+		}
+
+		tables = append(tables, table)
+	}
+
+	return sortTables(tables)
+}
+
+// PrimaryKey returns the names of a table's primary key columns.
+func PrimaryKey(t model.Table) []string {
+	columns := lo.Filter(t.Columns, func(c model.Column, _ int) bool {
+		return !c.Suppress
+	})
+
+	pk := lo.FilterMap(columns, func(c model.Column, _ int) (string, bool) {
+		return c.Name, c.PrimaryKey
+	})
+	if len(pk) > 0 {
+		return pk
+	}
+
+	if lo.ContainsBy(columns, func(c model.Column) bool { return c.Name == "id" }) {
+		return []string{"id"}
+	}
+
+	return nil
+}
+
+func sortTables(tables []Table) ([]Table, error) {
+	sorted, err := graph.SortBy(tables, func(t Table) string {
+		return t.Name
+	}, func(t Table) []string {
+		return lo.Map(t.ForeignKeys, func(fk ForeignKey, _ int) string {
+			return fk.RefTable
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ordering tables: %w", err)
+	}
+
+	return sorted, nil
+}
+
+// resolver determines the types of columns, following references to the
+// columns that their values come from.
+type resolver struct {
+	tables   map[string]model.Table
+	resolved map[generator.Reference]Column
+}
+
+func (r resolver) resolve(table, column string, visiting []generator.Reference) (Column, error) {
+	key := generator.Reference{Table: table, Column: column}
+	if c, ok := r.resolved[key]; ok {
+		return c, nil
+	}
+
+	result := Column{Name: column, Type: model.DataTypeString}
+
+	// Columns of inputs and circular references can't be inferred.
+	col, ok := lo.Find(r.tables[table].Columns, func(c model.Column) bool {
+		return c.Name == column
+	})
+	if !ok || lo.Contains(visiting, key) {
+		return result, nil
+	}
+
+	ref, isRef, err := generator.ColumnReference(col)
+	if err != nil {
+		return Column{}, err
+	}
+
+	switch {
+	case col.DataType != "":
+		result.Type = col.DataType
+
+	case isRef:
+		refColumn, err := r.resolve(ref.Table, ref.Column, append(visiting, key))
+		if err != nil {
+			return Column{}, err
+		}
+		result.Type, result.Length = refColumn.Type, refColumn.Length
+
+	default:
+		if result.Type, result.Length, err = inferType(col); err != nil {
+			return Column{}, err
+		}
+	}
+
+	r.resolved[key] = result
+	return result, nil
+}
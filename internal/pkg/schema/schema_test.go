@@ -0,0 +1,192 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromConfig(t *testing.T) {
+	c, err := model.LoadConfig(strings.NewReader(`
+tables:
+  - name: pet
+    columns:
+      - name: pet_id
+        type: cuid2
+        primary_key: true
+        processor:
+          length: 16
+      - name: owner_id
+        type: ref
+        processor:
+          table: person
+          column: id
+      - name: owner_name
+        type: match
+        processor:
+          source_table: person
+          source_column: id
+          source_value: name
+          match_column: owner_id
+      - name: internal
+        type: const
+        suppress: true
+        processor:
+          values: [a]
+
+  - name: person
+    count: 10
+    columns:
+      - name: id
+        type: inc
+        processor:
+          start: 1
+      - name: name
+        type: gen
+        processor:
+          value: ${first_name}
+      - name: team
+        type: ref
+        processor:
+          table: staging
+          column: id
+
+  - name: staging
+    suppress: true
+    columns:
+      - name: id
+        type: gen
+        processor:
+          value: ${uuid}
+`), ".")
+	assert.NoError(t, err)
+
+	tables, err := FromConfig(c)
+	assert.NoError(t, err)
+
+	exp := []Table{
+		{
+			Name: "person",
+			Columns: []Column{
+				{Name: "id", Type: model.DataTypeInt},
+				{Name: "name", Type: model.DataTypeString},
+				{Name: "team", Type: model.DataTypeUUID},
+			},
+			PrimaryKey: []string{"id"},
+		},
+		{
+			Name: "pet",
+			Columns: []Column{
+				{Name: "pet_id", Type: model.DataTypeString, Length: 16},
+				{Name: "owner_id", Type: model.DataTypeInt},
+				{Name: "owner_name", Type: model.DataTypeString},
+			},
+			PrimaryKey: []string{"pet_id"},
+			ForeignKeys: []ForeignKey{
+				{Columns: []string{"owner_id"}, RefTable: "person", RefColumns: []string{"id"}},
+			},
+		},
+	}
+
+	assert.Equal(t, exp, tables)
+}
+
+func TestFromConfigCycle(t *testing.T) {
+	c, err := model.LoadConfig(strings.NewReader(`
+tables:
+  - name: a
+    columns:
+      - name: id
+        type: ref
+        processor:
+          table: b
+          column: id
+  - name: b
+    columns:
+      - name: id
+        type: ref
+        processor:
+          table: a
+          column: id
+`), ".")
+	assert.NoError(t, err)
+
+	_, err = FromConfig(c)
+	assert.EqualError(t, err, "ordering tables: dependency cycle detected: a -> b -> a")
+}
+
+func TestInferType(t *testing.T) {
+	cases := []struct {
+		name      string
+		column    string
+		expType   model.DataType
+		expLength int
+	}{
+		{name: "inc", column: "{type: inc, processor: {start: 1}}", expType: model.DataTypeInt},
+		{name: "inc formatted", column: "{type: inc, processor: {start: 1, format: 'P%03d'}}", expType: model.DataTypeString},
+		{name: "range int", column: "{type: range, processor: {type: int, from: 1, to: 10}}", expType: model.DataTypeInt},
+		{name: "range date", column: "{type: range, processor: {type: date, format: '2006-01-02'}}", expType: model.DataTypeDate},
+		{name: "range timestamp", column: "{type: range, processor: {type: date, format: '2006-01-02T15:04:05Z07:00'}}", expType: model.DataTypeTimestamp},
+		{name: "rand int", column: "{type: rand, processor: {type: int}}", expType: model.DataTypeInt},
+		{name: "rand float", column: "{type: rand, processor: {type: float64}}", expType: model.DataTypeFloat},
+		{name: "rand float formatted", column: "{type: rand, processor: {type: float64, format: '%.2f'}}", expType: model.DataTypeDecimal},
+		{name: "rand date", column: "{type: rand, processor: {type: date}}", expType: model.DataTypeDate},
+		{name: "rel_date", column: "{type: rel_date, processor: {unit: day}}", expType: model.DataTypeDate},
+		{name: "cuid2", column: "{type: cuid2, processor: {length: 10}}", expType: model.DataTypeString, expLength: 10},
+		{name: "gen uuid", column: "{type: gen, processor: {value: '${uuid}'}}", expType: model.DataTypeUUID},
+		{name: "gen", column: "{type: gen, processor: {value: '${email}'}}", expType: model.DataTypeString},
+		{name: "set int", column: "{type: set, processor: {values: ['1', '2', '']}}", expType: model.DataTypeInt},
+		{name: "set bool", column: "{type: set, processor: {values: ['true', 'false']}}", expType: model.DataTypeBool},
+		{name: "set string", column: "{type: set, processor: {values: [a, '1']}}", expType: model.DataTypeString},
+		{name: "const decimal", column: "{type: const, processor: {values: ['1.5', '2']}}", expType: model.DataTypeDecimal},
+		{name: "expr", column: "{type: expr, processor: {expression: '1 + 1'}}", expType: model.DataTypeString},
+		{name: "no processor", column: "{type: gen}", expType: model.DataTypeString},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			config, err := model.LoadConfig(strings.NewReader("tables: [{name: t, columns: ["+c.column+"]}]"), ".")
+			assert.NoError(t, err)
+
+			actType, actLength, err := inferType(config.Tables[0].Columns[0])
+			assert.NoError(t, err)
+			assert.Equal(t, c.expType, actType)
+			assert.Equal(t, c.expLength, actLength)
+		})
+	}
+}
+
+func TestPrimaryKey(t *testing.T) {
+	cases := []struct {
+		name    string
+		columns []model.Column
+		exp     []string
+	}{
+		{
+			name:    "declared",
+			columns: []model.Column{{Name: "id"}, {Name: "a", PrimaryKey: true}, {Name: "b", PrimaryKey: true}},
+			exp:     []string{"a", "b"},
+		},
+		{
+			name:    "id fallback",
+			columns: []model.Column{{Name: "name"}, {Name: "id"}},
+			exp:     []string{"id"},
+		},
+		{
+			name:    "suppressed id",
+			columns: []model.Column{{Name: "id", Suppress: true}},
+		},
+		{
+			name:    "none",
+			columns: []model.Column{{Name: "name"}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.exp, PrimaryKey(model.Table{Columns: c.columns}))
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package writer
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (cw *csvWriter) WriteHeader(header []string) error {
+	return cw.w.Write(header)
+}
+
+func (cw *csvWriter) WriteRows(rows [][]string) error {
+	for _, row := range rows {
+		if err := cw.w.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cw *csvWriter) Flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
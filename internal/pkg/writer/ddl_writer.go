@@ -0,0 +1,53 @@
+package writer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/codingconcepts/dg/internal/pkg/dialect"
+	"github.com/codingconcepts/dg/internal/pkg/schema"
+	"github.com/samber/lo"
+)
+
+// WriteDDL writes a CREATE TABLE statement for each table, in the order
+// given.
+func WriteDDL(w io.Writer, d dialect.Dialect, tables []schema.Table) error {
+	bw := bufio.NewWriter(w)
+
+	for _, t := range tables {
+		keys := append([]string{}, t.PrimaryKey...)
+		for _, fk := range t.ForeignKeys {
+			keys = append(keys, fk.Columns...)
+		}
+
+		var lines []string
+		for _, c := range t.Columns {
+			columnType := d.ColumnType(c.Type, c.Length, lo.Contains(keys, c.Name))
+			lines = append(lines, fmt.Sprintf("%s %s", d.QuoteIdent(c.Name), columnType))
+		}
+
+		if len(t.PrimaryKey) > 0 {
+			lines = append(lines, fmt.Sprintf("PRIMARY KEY (%s)", quoteIdents(d, t.PrimaryKey)))
+		}
+
+		for _, fk := range t.ForeignKeys {
+			lines = append(lines, fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s (%s)",
+				quoteIdents(d, fk.Columns),
+				d.QuoteIdent(fk.RefTable),
+				quoteIdents(d, fk.RefColumns)))
+		}
+
+		fmt.Fprintf(bw, "CREATE TABLE %s (\n  %s\n);\n\n", d.QuoteIdent(t.Name), strings.Join(lines, ",\n  "))
+	}
+
+	return bw.Flush()
+}
+
+func quoteIdents(d dialect.Dialect, names []string) string {
+	quoted := lo.Map(names, func(name string, _ int) string {
+		return d.QuoteIdent(name)
+	})
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,86 @@
+package writer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/codingconcepts/dg/internal/pkg/dialect"
+	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/codingconcepts/dg/internal/pkg/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteDDL(t *testing.T) {
+	tables := []schema.Table{
+		{
+			Name: "person",
+			Columns: []schema.Column{
+				{Name: "id", Type: model.DataTypeString},
+				{Name: "name", Type: model.DataTypeString},
+				{Name: "code", Type: model.DataTypeString, Length: 8},
+			},
+			PrimaryKey: []string{"id"},
+		},
+		{
+			Name: "pet",
+			Columns: []schema.Column{
+				{Name: "person_id", Type: model.DataTypeString},
+				{Name: "born", Type: model.DataTypeDate},
+				{Name: "weight", Type: model.DataTypeDecimal},
+			},
+			ForeignKeys: []schema.ForeignKey{
+				{Columns: []string{"person_id"}, RefTable: "person", RefColumns: []string{"id"}},
+			},
+		},
+	}
+
+	cases := []struct {
+		dialect string
+		exp     string
+	}{
+		{
+			dialect: dialect.PostgreSQL,
+			exp: `CREATE TABLE "person" (
+  "id" TEXT,
+  "name" TEXT,
+  "code" VARCHAR(8),
+  PRIMARY KEY ("id")
+);
+
+CREATE TABLE "pet" (
+  "person_id" TEXT,
+  "born" DATE,
+  "weight" NUMERIC,
+  FOREIGN KEY ("person_id") REFERENCES "person" ("id")
+);
+
+`,
+		},
+		{
+			dialect: dialect.MySQL,
+			exp: "CREATE TABLE `person` (\n" +
+				"  `id` VARCHAR(255),\n" +
+				"  `name` TEXT,\n" +
+				"  `code` VARCHAR(8),\n" +
+				"  PRIMARY KEY (`id`)\n" +
+				");\n\n" +
+				"CREATE TABLE `pet` (\n" +
+				"  `person_id` VARCHAR(255),\n" +
+				"  `born` DATE,\n" +
+				"  `weight` DECIMAL(20,6),\n" +
+				"  FOREIGN KEY (`person_id`) REFERENCES `person` (`id`)\n" +
+				");\n\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.dialect, func(t *testing.T) {
+			d, err := dialect.Get(c.dialect)
+			assert.NoError(t, err)
+
+			buf := &bytes.Buffer{}
+			assert.NoError(t, WriteDDL(buf, d, tables))
+			assert.Equal(t, c.exp, buf.String())
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package writer
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/codingconcepts/dg/internal/pkg/dialect"
+)
+
+// Supported import dialects.
+const (
+	ImportCockroachDB          = "cockroachdb"
+	ImportCockroachDBHTTP      = "cockroachdb-http"
+	ImportCockroachDBNodelocal = "cockroachdb-nodelocal"
+	ImportPostgreSQL           = "postgres"
+	ImportMySQL                = "mysql"
+	ImportSQLite               = "sqlite"
+	ImportDuckDB               = "duckdb"
+)
+
+var funcs = template.FuncMap{"join": strings.Join}
+
+// importTemplates hold the statements that import a single CSV file, each of
+// which treats empty values as nulls.
+var importTemplates = map[string]*template.Template{
+	ImportCockroachDBHTTP: template.Must(template.New("cockroachdb-http").Funcs(funcs).Parse(
+		`IMPORT INTO {{.Table}} ({{join .Columns ", "}})
+CSV DATA (
+    {{.URL}}
+)
+WITH skip='1', nullif = '', allow_quoted_null;
+
+`)),
+
+	ImportCockroachDBNodelocal: template.Must(template.New("cockroachdb-nodelocal").Funcs(funcs).Parse(
+		`-- cockroach nodelocal upload {{.LocalPath}} {{.File}}
+IMPORT INTO {{.Table}} ({{join .Columns ", "}})
+CSV DATA (
+    {{.NodelocalURL}}
+)
+WITH skip='1', nullif = '', allow_quoted_null;
+
+`)),
+
+	ImportPostgreSQL: template.Must(template.New("postgres").Funcs(funcs).Parse(
+		`\COPY {{.Table}} ({{join .Columns ", "}}) FROM {{.Path}} WITH (FORMAT csv, HEADER true, NULL '')
+
+`)),
+
+	ImportMySQL: template.Must(template.New("mysql").Funcs(funcs).Parse(
+		`LOAD DATA LOCAL INFILE {{.Path}}
+INTO TABLE {{.Table}}
+FIELDS TERMINATED BY ',' OPTIONALLY ENCLOSED BY '"' ESCAPED BY ''
+LINES TERMINATED BY '\n'
+IGNORE 1 LINES
+({{join .Variables ", "}})
+SET {{range $i, $c := .Columns}}{{if $i}}, {{end}}{{$c}} = NULLIF({{index $.Variables $i}}, ''){{end}};
+
+`)),
+
+	ImportSQLite: template.Must(template.New("sqlite").Funcs(funcs).Parse(
+		`.import --csv --skip 1 {{.LocalPath}} {{.Name}}
+UPDATE {{.Table}} SET {{range $i, $c := .Columns}}{{if $i}}, {{end}}{{$c}} = NULLIF({{$c}}, ''){{end}};
+
+`)),
+
+	ImportDuckDB: template.Must(template.New("duckdb").Funcs(funcs).Parse(
+		`COPY {{.Table}} ({{join .Columns ", "}}) FROM {{.Path}} (FORMAT csv, HEADER true, NULLSTR '');
+
+`)),
+}
+
+// importDialects map import dialects to the SQL dialects used to quote their
+// identifiers and strings.
+var importDialects = map[string]string{
+	ImportCockroachDBHTTP:      dialect.CockroachDB,
+	ImportCockroachDBNodelocal: dialect.CockroachDB,
+	ImportPostgreSQL:           dialect.PostgreSQL,
+	ImportMySQL:                dialect.MySQL,
+	ImportSQLite:               dialect.SQLite,
+	ImportDuckDB:               dialect.DuckDB,
+}
+
+// ImportOptions configures the statements written by an ImportWriter.
+type ImportOptions struct {
+	// Dialect is the database (and import method) to write statements for.
+	// CockroachDB imports files over HTTP if a Port is provided and from
+	// nodelocal storage otherwise.
+	Dialect string
+
+	// Dir is the directory that CSV files are written to.
+	Dir string
+
+	// Port is the port that CSV files are served from (0 if they're not
+	// being served).
+	Port int
+}
+
+// ImportWriter writes statements that import CSV files into a database.
+type ImportWriter struct {
+	w       io.Writer
+	opts    ImportOptions
+	tmpl    *template.Template
+	dialect dialect.Dialect
+}
+
+type importData struct {
+	Name         string
+	File         string
+	Table        string
+	Columns      []string
+	Variables    []string
+	Path         string
+	LocalPath    string
+	URL          string
+	NodelocalURL string
+}
+
+// NewImportWriter returns an ImportWriter for the given options.
+func NewImportWriter(w io.Writer, opts ImportOptions) (*ImportWriter, error) {
+	if opts.Dialect == ImportCockroachDB || opts.Dialect == "" {
+		opts.Dialect = ImportCockroachDBNodelocal
+		if opts.Port != 0 {
+			opts.Dialect = ImportCockroachDBHTTP
+		}
+	}
+
+	tmpl, ok := importTemplates[opts.Dialect]
+	if !ok {
+		return nil, fmt.Errorf("%q is not a valid import dialect", opts.Dialect)
+	}
+
+	if opts.Dialect == ImportCockroachDBHTTP && opts.Port == 0 {
+		return nil, fmt.Errorf("%s imports require files to be served on a port", opts.Dialect)
+	}
+
+	d, err := dialect.Get(importDialects[opts.Dialect])
+	if err != nil {
+		return nil, fmt.Errorf("getting dialect: %w", err)
+	}
+
+	return &ImportWriter{
+		w:       w,
+		opts:    opts,
+		tmpl:    tmpl,
+		dialect: d,
+	}, nil
+}
+
+// WriteTable writes the statement that imports a table's CSV file.
+func (iw *ImportWriter) WriteTable(name string, header []string) error {
+	file := name + Extension(FormatCSV)
+	localPath := path.Join(iw.opts.Dir, file)
+
+	data := importData{
+		Name:         name,
+		File:         file,
+		Table:        iw.dialect.QuoteIdent(name),
+		Path:         iw.dialect.QuoteString(localPath),
+		LocalPath:    strconv.Quote(localPath),
+		URL:          iw.dialect.QuoteString(fmt.Sprintf("http://localhost:%d/%s", iw.opts.Port, url.PathEscape(file))),
+		NodelocalURL: iw.dialect.QuoteString("nodelocal://1/" + file),
+	}
+
+	for i, column := range header {
+		data.Columns = append(data.Columns, iw.dialect.QuoteIdent(column))
+		data.Variables = append(data.Variables, fmt.Sprintf("@c%d", i+1))
+	}
+
+	return iw.tmpl.Execute(iw.w, data)
+}
@@ -0,0 +1,95 @@
+package writer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportWriter(t *testing.T) {
+	header := []string{"id", "name"}
+
+	cases := []struct {
+		name string
+		opts ImportOptions
+		exp  string
+	}{
+		{
+			name: "cockroachdb served",
+			opts: ImportOptions{Dialect: ImportCockroachDB, Dir: "out", Port: 3000},
+			exp: `IMPORT INTO "person" ("id", "name")
+CSV DATA (
+    'http://localhost:3000/person.csv'
+)
+WITH skip='1', nullif = '', allow_quoted_null;
+
+`,
+		},
+		{
+			name: "cockroachdb not served",
+			opts: ImportOptions{Dir: "out"},
+			exp: `-- cockroach nodelocal upload "out/person.csv" person.csv
+IMPORT INTO "person" ("id", "name")
+CSV DATA (
+    'nodelocal://1/person.csv'
+)
+WITH skip='1', nullif = '', allow_quoted_null;
+
+`,
+		},
+		{
+			name: "postgres",
+			opts: ImportOptions{Dialect: ImportPostgreSQL, Dir: "out"},
+			exp: `\COPY "person" ("id", "name") FROM 'out/person.csv' WITH (FORMAT csv, HEADER true, NULL '')
+
+`,
+		},
+		{
+			name: "mysql",
+			opts: ImportOptions{Dialect: ImportMySQL, Dir: "out"},
+			exp: "LOAD DATA LOCAL INFILE 'out/person.csv'\n" +
+				"INTO TABLE `person`\n" +
+				"FIELDS TERMINATED BY ',' OPTIONALLY ENCLOSED BY '\"' ESCAPED BY ''\n" +
+				"LINES TERMINATED BY '\\n'\n" +
+				"IGNORE 1 LINES\n" +
+				"(@c1, @c2)\n" +
+				"SET `id` = NULLIF(@c1, ''), `name` = NULLIF(@c2, '');\n\n",
+		},
+		{
+			name: "sqlite",
+			opts: ImportOptions{Dialect: ImportSQLite, Dir: "out"},
+			exp: `.import --csv --skip 1 "out/person.csv" person
+UPDATE "person" SET "id" = NULLIF("id", ''), "name" = NULLIF("name", '');
+
+`,
+		},
+		{
+			name: "duckdb",
+			opts: ImportOptions{Dialect: ImportDuckDB, Dir: "out"},
+			exp: `COPY "person" ("id", "name") FROM 'out/person.csv' (FORMAT csv, HEADER true, NULLSTR '');
+
+`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+
+			w, err := NewImportWriter(buf, c.opts)
+			assert.NoError(t, err)
+
+			assert.NoError(t, w.WriteTable("person", header))
+			assert.Equal(t, c.exp, buf.String())
+		})
+	}
+}
+
+func TestImportWriterInvalidOptions(t *testing.T) {
+	_, err := NewImportWriter(&bytes.Buffer{}, ImportOptions{Dialect: "oracle"})
+	assert.EqualError(t, err, `"oracle" is not a valid import dialect`)
+
+	_, err = NewImportWriter(&bytes.Buffer{}, ImportOptions{Dialect: ImportCockroachDBHTTP})
+	assert.EqualError(t, err, "cockroachdb-http imports require files to be served on a port")
+}
@@ -0,0 +1,217 @@
+package writer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/codingconcepts/dg/internal/pkg/model"
+)
+
+// jsonWriter writes rows as JSON objects keyed by the header, either as a
+// single array or as newline-delimited objects.
+type jsonWriter struct {
+	w         *bufio.Writer
+	opts      Options
+	delimited bool
+
+	keys  []string
+	types []model.DataType
+	rows  int
+}
+
+func newJSONWriter(w io.Writer, opts Options, delimited bool) *jsonWriter {
+	return &jsonWriter{
+		w:         bufio.NewWriter(w),
+		opts:      opts,
+		delimited: delimited,
+	}
+}
+
+func (jw *jsonWriter) WriteHeader(header []string) error {
+	jw.keys = make([]string, len(header))
+	jw.types = make([]model.DataType, len(header))
+	for i, h := range header {
+		jw.types[i] = jw.opts.Types[h]
+
+		key, err := marshalString(h)
+		if err != nil {
+			return err
+		}
+		jw.keys[i] = key
+	}
+
+	if !jw.delimited {
+		_, err := jw.w.WriteString("[")
+		return err
+	}
+	return nil
+}
+
+func (jw *jsonWriter) WriteRows(rows [][]string) error {
+	for _, row := range rows {
+		if err := jw.writeRow(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (jw *jsonWriter) writeRow(row []string) error {
+	switch {
+	case jw.delimited:
+	case jw.rows == 0:
+		jw.w.WriteString("\n  ")
+	default:
+		jw.w.WriteString(",\n  ")
+	}
+	jw.rows++
+
+	jw.w.WriteByte('{')
+	fields := 0
+	for i, key := range jw.keys {
+		var value string
+		if i < len(row) {
+			value = row[i]
+		}
+
+		if value == "" && jw.opts.Empty == EmptyOmit {
+			continue
+		}
+
+		if fields > 0 {
+			jw.w.WriteByte(',')
+		}
+		fields++
+
+		jw.w.WriteString(key)
+		jw.w.WriteByte(':')
+
+		if err := jw.writeValue(value, jw.types[i]); err != nil {
+			return err
+		}
+	}
+	jw.w.WriteByte('}')
+
+	if jw.delimited {
+		jw.w.WriteByte('\n')
+	}
+	return nil
+}
+
+func (jw *jsonWriter) writeValue(value string, dt model.DataType) error {
+	if value == "" && jw.opts.Empty == EmptyNull {
+		_, err := jw.w.WriteString("null")
+		return err
+	}
+
+	encoded, err := jsonLiteral(value, dt)
+	if err != nil {
+		return err
+	}
+	_, err = jw.w.WriteString(encoded)
+	return err
+}
+
+// jsonLiteral encodes a value as JSON according to its data type. Empty
+// values of non-string types can't be represented and are written as
+// strings.
+func jsonLiteral(value string, dt model.DataType) (string, error) {
+	if value == "" {
+		return `""`, nil
+	}
+
+	switch dt {
+	case model.DataTypeInt:
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("encoding %q as json int: %w", value, err)
+		}
+		return strconv.FormatInt(i, 10), nil
+
+	case model.DataTypeFloat:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", fmt.Errorf("encoding %q as json float: %w", value, err)
+		}
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return "", fmt.Errorf("encoding %q as json float: not a finite number", value)
+		}
+		return strconv.FormatFloat(f, 'g', -1, 64), nil
+
+	case model.DataTypeDecimal:
+		if err := dt.Check(value); err != nil {
+			return "", fmt.Errorf("encoding json decimal: %w", err)
+		}
+		return normalizeDecimal(value), nil
+
+	case model.DataTypeBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("encoding %q as json bool: %w", value, err)
+		}
+		return strconv.FormatBool(b), nil
+
+	case model.DataTypeJSON:
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, []byte(value)); err != nil {
+			return "", fmt.Errorf("encoding %q as json: %w", value, err)
+		}
+		return buf.String(), nil
+
+	default:
+		return marshalString(value)
+	}
+}
+
+// normalizeDecimal rewrites a valid decimal (which may have a leading plus
+// sign, leading zeros or a bare decimal point) as a valid JSON number,
+// without losing precision.
+func normalizeDecimal(value string) string {
+	sign := ""
+	switch value[0] {
+	case '-':
+		sign = "-"
+		value = value[1:]
+	case '+':
+		value = value[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(value, ".")
+	whole = strings.TrimLeft(whole, "0")
+	if whole == "" {
+		whole = "0"
+	}
+
+	if fraction == "" {
+		return sign + whole
+	}
+	return sign + whole + "." + fraction
+}
+
+// marshalString encodes a string as JSON without escaping HTML characters,
+// which json.Marshal does by default.
+func marshalString(s string) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		return "", err
+	}
+	return string(bytes.TrimRight(buf.Bytes(), "\n")), nil
+}
+
+func (jw *jsonWriter) Flush() error {
+	if !jw.delimited {
+		if jw.rows > 0 {
+			jw.w.WriteString("\n")
+		}
+		jw.w.WriteString("]\n")
+	}
+	return jw.w.Flush()
+}
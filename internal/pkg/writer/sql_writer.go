@@ -0,0 +1,117 @@
+package writer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/codingconcepts/dg/internal/pkg/dialect"
+)
+
+// DefaultBatchSize is the number of rows written per INSERT statement when
+// no batch size is given.
+const DefaultBatchSize = 100
+
+type sqlWriter struct {
+	w       *bufio.Writer
+	opts    Options
+	prefix  string
+	header  []string
+	pending [][]string
+}
+
+func newSQLWriter(w io.Writer, opts Options) (*sqlWriter, error) {
+	if opts.Table == "" {
+		return nil, fmt.Errorf("missing table name for sql output")
+	}
+	if opts.Dialect.Name == "" {
+		d, err := dialect.Get(dialect.CockroachDB)
+		if err != nil {
+			return nil, err
+		}
+		opts.Dialect = d
+	}
+
+	switch {
+	case opts.BatchSize < 0:
+		return nil, fmt.Errorf("batch size must be positive")
+	case opts.BatchSize == 0:
+		opts.BatchSize = DefaultBatchSize
+	}
+	if max := opts.Dialect.MaxBatchSize; max > 0 && opts.BatchSize > max {
+		opts.BatchSize = max
+	}
+
+	return &sqlWriter{
+		w:    bufio.NewWriter(w),
+		opts: opts,
+	}, nil
+}
+
+func (sw *sqlWriter) WriteHeader(header []string) error {
+	sw.header = header
+
+	columns := make([]string, len(header))
+	for i, name := range header {
+		columns[i] = sw.opts.Dialect.QuoteIdent(name)
+	}
+
+	sw.prefix = fmt.Sprintf("INSERT INTO %s (%s) VALUES",
+		sw.opts.Dialect.QuoteIdent(sw.opts.Table),
+		strings.Join(columns, ", "))
+
+	return nil
+}
+
+func (sw *sqlWriter) WriteRows(rows [][]string) error {
+	for _, row := range rows {
+		sw.pending = append(sw.pending, row)
+		if len(sw.pending) == sw.opts.BatchSize {
+			if err := sw.writeBatch(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (sw *sqlWriter) Flush() error {
+	if err := sw.writeBatch(); err != nil {
+		return err
+	}
+	return sw.w.Flush()
+}
+
+// writeBatch writes the pending rows as a single multi-row INSERT statement.
+func (sw *sqlWriter) writeBatch() error {
+	if len(sw.pending) == 0 {
+		return nil
+	}
+
+	sw.w.WriteString(sw.prefix)
+	sw.w.WriteString("\n")
+
+	for i, row := range sw.pending {
+		values := make([]string, len(row))
+		for j, value := range row {
+			literal, err := sw.opts.Dialect.Literal(value, sw.opts.Types[sw.header[j]])
+			if err != nil {
+				return fmt.Errorf("column %q: %w", sw.header[j], err)
+			}
+			values[j] = literal
+		}
+
+		sw.w.WriteString("\t(")
+		sw.w.WriteString(strings.Join(values, ", "))
+		if i < len(sw.pending)-1 {
+			sw.w.WriteString("),\n")
+		} else {
+			sw.w.WriteString(");\n")
+		}
+	}
+
+	sw.pending = sw.pending[:0]
+	_, err := sw.w.WriteString("\n")
+	return err
+}
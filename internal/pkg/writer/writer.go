@@ -0,0 +1,95 @@
+package writer
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/codingconcepts/dg/internal/pkg/dialect"
+	"github.com/codingconcepts/dg/internal/pkg/model"
+)
+
+// Supported output formats.
+const (
+	FormatCSV    = "csv"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatSQL    = "sql"
+)
+
+// Supported ways of writing empty values to formats that have a notion of
+// null.
+const (
+	EmptyNull   = "null"
+	EmptyOmit   = "omit"
+	EmptyString = "string"
+)
+
+// Writer writes the rows of a table to an output.
+type Writer interface {
+	// WriteHeader writes the column names, and must be called before any
+	// calls to WriteRows.
+	WriteHeader(header []string) error
+
+	// WriteRows writes rows of values, in the same order as the header.
+	WriteRows(rows [][]string) error
+
+	// Flush writes any buffered data and terminates the output.
+	Flush() error
+}
+
+// Options configures how a Writer encodes values.
+type Options struct {
+	// Empty determines how empty values are written (one of EmptyNull,
+	// EmptyOmit or EmptyString). Formats that don't support null, like CSV,
+	// ignore it.
+	Empty string
+
+	// Types holds the declared data types of columns, keyed by column name.
+	// Typed formats use them to encode values; columns without a type are
+	// written as strings.
+	Types map[string]model.DataType
+
+	// Table is the name of the table being written, which formats that
+	// reference it, like SQL, require.
+	Table string
+
+	// Dialect determines the quoting and escaping rules of SQL output
+	// (defaults to CockroachDB).
+	Dialect dialect.Dialect
+
+	// BatchSize is the number of rows per INSERT statement in SQL output
+	// (defaults to DefaultBatchSize, capped at the dialect's maximum).
+	BatchSize int
+}
+
+// New returns a Writer for the given format.
+func New(format string, w io.Writer, opts Options) (Writer, error) {
+	switch opts.Empty {
+	case "":
+		opts.Empty = EmptyNull
+	case EmptyNull, EmptyOmit, EmptyString:
+	default:
+		return nil, fmt.Errorf("%q is not a valid empty value option", opts.Empty)
+	}
+
+	switch format {
+	case FormatCSV, "":
+		return newCSVWriter(w), nil
+	case FormatJSON:
+		return newJSONWriter(w, opts, false), nil
+	case FormatNDJSON:
+		return newJSONWriter(w, opts, true), nil
+	case FormatSQL:
+		return newSQLWriter(w, opts)
+	default:
+		return nil, fmt.Errorf("%q is not a valid output format", format)
+	}
+}
+
+// Extension returns the file extension for a given format.
+func Extension(format string) string {
+	if format == "" {
+		return "." + FormatCSV
+	}
+	return "." + format
+}
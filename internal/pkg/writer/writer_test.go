@@ -0,0 +1,219 @@
+package writer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/codingconcepts/dg/internal/pkg/dialect"
+	"github.com/codingconcepts/dg/internal/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriter(t *testing.T) {
+	header := []string{"id", "name"}
+	rows := [][]string{
+		{"1", "Alice"},
+		{"2", ""},
+		{"3", `"Bob" <b@example.com>`},
+	}
+
+	cases := []struct {
+		name   string
+		format string
+		opts   Options
+		rows   [][]string
+		exp    string
+	}{
+		{
+			name:   "csv",
+			format: FormatCSV,
+			rows:   rows,
+			exp:    "id,name\n1,Alice\n2,\n3,\"\"\"Bob\"\" <b@example.com>\"\n",
+		},
+		{
+			name:   "json empty as null",
+			format: FormatJSON,
+			rows:   rows,
+			exp:    "[\n  {\"id\":\"1\",\"name\":\"Alice\"},\n  {\"id\":\"2\",\"name\":null},\n  {\"id\":\"3\",\"name\":\"\\\"Bob\\\" <b@example.com>\"}\n]\n",
+		},
+		{
+			name:   "json empty omitted",
+			format: FormatJSON,
+			opts:   Options{Empty: EmptyOmit},
+			rows:   rows[:2],
+			exp:    "[\n  {\"id\":\"1\",\"name\":\"Alice\"},\n  {\"id\":\"2\"}\n]\n",
+		},
+		{
+			name:   "json empty as string",
+			format: FormatJSON,
+			opts:   Options{Empty: EmptyString},
+			rows:   rows[1:2],
+			exp:    "[\n  {\"id\":\"2\",\"name\":\"\"}\n]\n",
+		},
+		{
+			name:   "json no rows",
+			format: FormatJSON,
+			exp:    "[]\n",
+		},
+		{
+			name:   "ndjson",
+			format: FormatNDJSON,
+			rows:   rows[:2],
+			exp:    "{\"id\":\"1\",\"name\":\"Alice\"}\n{\"id\":\"2\",\"name\":null}\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+
+			w, err := New(c.format, buf, c.opts)
+			assert.NoError(t, err)
+
+			assert.NoError(t, w.WriteHeader(header))
+			assert.NoError(t, w.WriteRows(c.rows))
+			assert.NoError(t, w.Flush())
+
+			assert.Equal(t, c.exp, buf.String())
+		})
+	}
+}
+
+func TestJSONWriterTypes(t *testing.T) {
+	header := []string{"id", "price", "score", "active", "meta", "name"}
+	types := map[string]model.DataType{
+		"id":     model.DataTypeInt,
+		"price":  model.DataTypeDecimal,
+		"score":  model.DataTypeFloat,
+		"active": model.DataTypeBool,
+		"meta":   model.DataTypeJSON,
+	}
+
+	buf := &bytes.Buffer{}
+	w, err := New(FormatNDJSON, buf, Options{Types: types})
+	assert.NoError(t, err)
+
+	assert.NoError(t, w.WriteHeader(header))
+	assert.NoError(t, w.WriteRows([][]string{
+		{"007", "+.50", "1.5e3", "t", "{\n  \"a\": [1, 2]\n}", "1"},
+		{"-1", "-010.", "", "false", "null", ""},
+	}))
+	assert.NoError(t, w.Flush())
+
+	exp := `{"id":7,"price":0.50,"score":1500,"active":true,"meta":{"a":[1,2]},"name":"1"}
+{"id":-1,"price":-10,"score":null,"active":false,"meta":null,"name":null}
+`
+	assert.Equal(t, exp, buf.String())
+}
+
+func TestJSONWriterTypesInvalid(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w, err := New(FormatJSON, buf, Options{Types: map[string]model.DataType{"id": model.DataTypeInt}})
+	assert.NoError(t, err)
+
+	assert.NoError(t, w.WriteHeader([]string{"id"}))
+	assert.Error(t, w.WriteRows([][]string{{"one"}}))
+}
+
+func TestSQLWriter(t *testing.T) {
+	header := []string{"id", "name", "active"}
+	types := map[string]model.DataType{
+		"id":     model.DataTypeInt,
+		"active": model.DataTypeBool,
+	}
+	rows := [][]string{
+		{"1", "O'Brien", "true"},
+		{"2", "", "false"},
+		{"3", "Carol", ""},
+	}
+
+	postgres, err := dialect.Get(dialect.PostgreSQL)
+	assert.NoError(t, err)
+
+	mysql, err := dialect.Get(dialect.MySQL)
+	assert.NoError(t, err)
+
+	cases := []struct {
+		name string
+		opts Options
+		rows [][]string
+		exp  string
+	}{
+		{
+			name: "single batch",
+			opts: Options{Table: "person", Dialect: postgres, Types: types},
+			rows: rows,
+			exp: `INSERT INTO "person" ("id", "name", "active") VALUES
+	(1, 'O''Brien', true),
+	(2, NULL, false),
+	(3, 'Carol', NULL);
+
+`,
+		},
+		{
+			name: "multiple batches",
+			opts: Options{Table: "person", Dialect: mysql, Types: types, BatchSize: 2},
+			rows: rows,
+			exp: "INSERT INTO `person` (`id`, `name`, `active`) VALUES\n" +
+				"\t(1, 'O''Brien', TRUE),\n" +
+				"\t(2, NULL, FALSE);\n\n" +
+				"INSERT INTO `person` (`id`, `name`, `active`) VALUES\n" +
+				"\t(3, 'Carol', NULL);\n\n",
+		},
+		{
+			name: "no rows",
+			opts: Options{Table: "person", Dialect: postgres},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+
+			w, err := New(FormatSQL, buf, c.opts)
+			assert.NoError(t, err)
+
+			assert.NoError(t, w.WriteHeader(header))
+			assert.NoError(t, w.WriteRows(c.rows))
+			assert.NoError(t, w.Flush())
+
+			assert.Equal(t, c.exp, buf.String())
+		})
+	}
+}
+
+func TestSQLWriterBatchSize(t *testing.T) {
+	sqlserver, err := dialect.Get(dialect.SQLServer)
+	assert.NoError(t, err)
+
+	w, err := newSQLWriter(&bytes.Buffer{}, Options{Table: "t", Dialect: sqlserver, BatchSize: 5000})
+	assert.NoError(t, err)
+	assert.Equal(t, 1000, w.opts.BatchSize)
+
+	w, err = newSQLWriter(&bytes.Buffer{}, Options{Table: "t"})
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultBatchSize, w.opts.BatchSize)
+	assert.Equal(t, dialect.CockroachDB, w.opts.Dialect.Name)
+
+	_, err = newSQLWriter(&bytes.Buffer{}, Options{Table: "t", BatchSize: -1})
+	assert.EqualError(t, err, "batch size must be positive")
+
+	_, err = newSQLWriter(&bytes.Buffer{}, Options{})
+	assert.EqualError(t, err, "missing table name for sql output")
+}
+
+func TestWriterInvalidOptions(t *testing.T) {
+	_, err := New("xml", &bytes.Buffer{}, Options{})
+	assert.EqualError(t, err, `"xml" is not a valid output format`)
+
+	_, err = New(FormatJSON, &bytes.Buffer{}, Options{Empty: "zero"})
+	assert.EqualError(t, err, `"zero" is not a valid empty value option`)
+}
+
+func TestExtension(t *testing.T) {
+	assert.Equal(t, ".csv", Extension(""))
+	assert.Equal(t, ".csv", Extension(FormatCSV))
+	assert.Equal(t, ".json", Extension(FormatJSON))
+	assert.Equal(t, ".ndjson", Extension(FormatNDJSON))
+	assert.Equal(t, ".sql", Extension(FormatSQL))
+}